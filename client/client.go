@@ -0,0 +1,212 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dghttp "github.com/darwinOrg/go-httpclient"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Get 以 form 标签将请求对象编码为查询参数，header 标签的字段作为请求头，调用 wrapper.Get 注册的接口。
+// 非指针字段的零值也会发送，以便传递显式的 0、false；需要使用服务端 default 标签的默认值时，把字段声明为指针或加上 omitempty
+func Get[T any, V any](ctx *dgctx.DgContext, hc *dghttp.DgHttpClient, rawUrl string, req *T) (V, error) {
+	var rt V
+
+	query, header := url.Values{}, http.Header{}
+	if req != nil {
+		encodeRequest(reflect.ValueOf(req).Elem(), query, header)
+	}
+	if len(query) > 0 {
+		if strings.Contains(rawUrl, "?") {
+			rawUrl += "&" + query.Encode()
+		} else {
+			rawUrl += "?" + query.Encode()
+		}
+	}
+
+	request, err := newRequest(ctx, http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return rt, err
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	return doRequest[V](ctx, hc, request)
+}
+
+// Post 以 json 编码请求对象，header 标签的字段同时作为请求头，调用 wrapper.Post 注册的接口
+func Post[T any, V any](ctx *dgctx.DgContext, hc *dghttp.DgHttpClient, rawUrl string, req *T) (V, error) {
+	var rt V
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return rt, err
+	}
+
+	request, err := newRequest(ctx, http.MethodPost, rawUrl, bytes.NewReader(body))
+	if err != nil {
+		return rt, err
+	}
+	request.Header.Set("Content-Type", gin.MIMEJSON)
+	if req != nil {
+		encodeRequest(reflect.ValueOf(req).Elem(), nil, request.Header)
+	}
+
+	return doRequest[V](ctx, hc, request)
+}
+
+func newRequest(ctx *dgctx.DgContext, method string, rawUrl string, body io.Reader) (*http.Request, error) {
+	innerCtx := ctx.GetInnerContext()
+	if innerCtx == nil {
+		innerCtx = context.Background()
+	}
+
+	request, err := http.NewRequestWithContext(innerCtx, method, rawUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	utils.WriteDgContextHeaders(ctx, request.Header)
+
+	return request, nil
+}
+
+func doRequest[V any](ctx *dgctx.DgContext, hc *dghttp.DgHttpClient, request *http.Request) (V, error) {
+	var rt V
+
+	resp, err := hc.DoRequestRaw(ctx, request)
+	if err != nil {
+		return rt, err
+	}
+
+	statusCode, _, body, err := dghttp.ExtractResponse(ctx, resp)
+	if err != nil {
+		return rt, err
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return rt, fmt.Errorf("%s %s response status code: %d, body: %s", request.Method, request.URL.Path, statusCode, body)
+	}
+
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &rt); err != nil {
+			return rt, err
+		}
+	}
+
+	return rt, nil
+}
+
+// encodeRequest 按服务端的绑定来源拆分请求对象：uri 字段由调用方拼入 url，直接跳过；header 字段作为请求头，零值不发送；
+// 其余字段作为查询参数，query 为 nil 时不收集（Post 的这些字段已在请求体中）
+func encodeRequest(v reflect.Value, query url.Values, header http.Header) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if field.Anonymous && field.Tag.Get("form") == "" {
+			encodeRequest(fv, query, header)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if tagName(field, "uri") != "" {
+			continue
+		}
+		if name := tagName(field, "header"); name != "" {
+			for _, value := range fieldValues(fv, true) {
+				header.Add(name, value)
+			}
+			continue
+		}
+		if query == nil {
+			continue
+		}
+
+		name, omitEmpty := queryName(field)
+		if name == "" {
+			continue
+		}
+		for _, value := range fieldValues(fv, omitEmpty) {
+			query.Add(name, value)
+		}
+	}
+}
+
+func fieldValues(fv reflect.Value, omitEmpty bool) []string {
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			break
+		}
+		fv = fv.Elem()
+	}
+
+	var values []string
+	switch fv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !fv.IsNil() {
+			values = append(values, fmt.Sprint(fv.Interface()))
+		}
+	case reflect.Slice, reflect.Array:
+		for j := 0; j < fv.Len(); j++ {
+			values = append(values, fmt.Sprint(fv.Index(j).Interface()))
+		}
+	case reflect.Struct, reflect.Map:
+		if tm, ok := fv.Interface().(time.Time); ok && !tm.IsZero() {
+			values = append(values, tm.Format(time.RFC3339))
+		}
+		// 其他嵌套对象无法按 form 绑定，直接跳过
+	default:
+		if !omitEmpty || !fv.IsZero() {
+			values = append(values, fmt.Sprint(fv.Interface()))
+		}
+	}
+	return values
+}
+
+func tagName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func queryName(field reflect.StructField) (name string, omitEmpty bool) {
+	for _, tag := range []string{"form", "json"} {
+		if tagValue, ok := field.Tag.Lookup(tag); ok {
+			parts := strings.Split(tagValue, ",")
+			if parts[0] == "-" {
+				return "", false
+			}
+			for _, opt := range parts[1:] {
+				omitEmpty = omitEmpty || opt == "omitempty"
+			}
+			if parts[0] != "" {
+				return parts[0], omitEmpty
+			}
+		}
+	}
+	return field.Name, omitEmpty
+}
@@ -0,0 +1,71 @@
+// tsgen 根据服务暴露的 OpenAPI 文档（wrapper.ServeOpenApi 或接口调试页面的 openapi.json）生成 TypeScript 客户端
+//
+//	go run github.com/darwinOrg/go-web/cmd/tsgen -spec http://localhost:8080/api-explorer/openapi.json -out api.ts
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/darwinOrg/go-web/codegen"
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+func main() {
+	spec := flag.String("spec", "", "OpenAPI 文档的文件路径或 URL")
+	out := flag.String("out", "", "输出文件，默认输出到标准输出")
+	int64Mode := flag.String("int64", string(codegen.INT64_MODE_STRING), "int64 字段类型: string 或 bigint")
+	flag.Parse()
+
+	if *spec == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	doc, err := loadOpenApiDoc(*spec)
+	if err != nil {
+		log.Fatalf("load openapi doc error: %v", err)
+	}
+
+	code, err := codegen.GenerateTypeScript(doc, &codegen.TypeScriptOptions{Int64Mode: codegen.Int64Mode(*int64Mode)})
+	if err != nil {
+		log.Fatalf("generate typescript error: %v", err)
+	}
+
+	if *out == "" {
+		_, _ = os.Stdout.Write(code)
+		return
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatalf("write %s error: %v", *out, err)
+	}
+}
+
+func loadOpenApiDoc(spec string) (*wrapper.OpenApiDoc, error) {
+	var data []byte
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		resp, err := http.Get(spec)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	return codegen.ParseOpenApiDoc(data)
+}
@@ -0,0 +1,262 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+const (
+	dgctxImportPath  = "github.com/darwinOrg/go-common/context"
+	dghttpImportPath = "github.com/darwinOrg/go-httpclient"
+	clientImportPath = "github.com/darwinOrg/go-web/client"
+)
+
+var (
+	qualifiedTypeRegexp = regexp.MustCompile(`([\w.\-~/]+)\.([A-Za-z_]\w*)`)
+	majorVersionRegexp  = regexp.MustCompile(`^v\d+$`)
+)
+
+type GoClientOptions struct {
+	PackageName string
+	// ClientName 为生成的客户端结构体名称，默认 Client
+	ClientName string
+}
+
+// GenerateGoClient 根据 RequestApis 生成基于 dghttp.DgHttpClient 的类型化客户端，请求和返回类型直接复用服务端定义
+func GenerateGoClient(apis []*wrapper.RequestApi, opts *GoClientOptions) ([]byte, error) {
+	if opts == nil || opts.PackageName == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	clientName := opts.ClientName
+	if clientName == "" {
+		clientName = "Client"
+	}
+
+	g := &goClientGenerator{
+		imports: map[string]string{},
+		aliases: map[string]string{},
+	}
+	// 客户端结构体始终用到 dghttp 和 strings，其余包在生成方法时按需导入，避免没有接口时出现未使用的导入
+	g.importAlias(dghttpImportPath, "dghttp")
+	g.importAlias("strings", "strings")
+
+	var methods bytes.Buffer
+	methodNames := map[string]bool{}
+	for _, api := range apis {
+		if err := g.writeMethod(&methods, clientName, api, methodNames); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "// Code generated by go-web codegen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", opts.PackageName)
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if alias := g.imports[p]; alias != p[strings.LastIndex(p, "/")+1:] {
+			_, _ = fmt.Fprintf(&buf, "\t%s %s\n", alias, strconv.Quote(p))
+		} else {
+			_, _ = fmt.Fprintf(&buf, "\t%s\n", strconv.Quote(p))
+		}
+	}
+	_, _ = fmt.Fprintf(&buf, ")\n\n")
+	_, _ = fmt.Fprintf(&buf, `type %[1]s struct {
+	hc      *dghttp.DgHttpClient
+	baseUrl string
+}
+
+func New%[1]s(hc *dghttp.DgHttpClient, baseUrl string) *%[1]s {
+	return &%[1]s{hc: hc, baseUrl: strings.TrimSuffix(baseUrl, "/")}
+}
+
+`, clientName)
+	buf.Write(methods.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+type goClientGenerator struct {
+	// 导入路径到别名
+	imports map[string]string
+	// 别名到导入路径
+	aliases map[string]string
+}
+
+func (g *goClientGenerator) writeMethod(buf *bytes.Buffer, clientName string, api *wrapper.RequestApi, methodNames map[string]bool) error {
+	var fn string
+	switch api.Method {
+	case http.MethodGet:
+		fn = "Get"
+	case http.MethodPost:
+		fn = "Post"
+	default:
+		return fmt.Errorf("unsupported method: %s", api.Method)
+	}
+
+	g.importAlias(dgctxImportPath, "dgctx")
+	g.importAlias(clientImportPath, "client")
+
+	fullPath := wrapper.OpenApiPath(api)
+	opId := wrapper.OpenApiOperationId(api.Method, fullPath)
+	methodName := strings.ToUpper(opId[:1]) + opId[1:]
+	if methodNames[methodName] {
+		return fmt.Errorf("duplicate method name %s for %s %s", methodName, api.Method, fullPath)
+	}
+	methodNames[methodName] = true
+
+	requestType, err := g.typeExpr(reflect.TypeOf(api.RequestObject).Elem())
+	if err != nil {
+		return fmt.Errorf("%s %s request type: %w", api.Method, fullPath, err)
+	}
+	responseType, err := g.typeExpr(reflect.TypeOf(api.ResponseObject).Elem())
+	if err != nil {
+		return fmt.Errorf("%s %s response type: %w", api.Method, fullPath, err)
+	}
+
+	var pathArgs, urlParts []string
+	static := ""
+	for _, segment := range strings.Split(fullPath, "/")[1:] {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			arg := goIdentifier(segment[1 : len(segment)-1])
+			pathArgs = append(pathArgs, arg+" string")
+			urlParts = append(urlParts, strconv.Quote(static+"/"), "url.PathEscape("+arg+")")
+			static = ""
+			g.importAlias("net/url", "url")
+		} else {
+			static += "/" + segment
+		}
+	}
+	if static != "" {
+		urlParts = append(urlParts, strconv.Quote(static))
+	}
+
+	args := append([]string{"ctx *dgctx.DgContext"}, pathArgs...)
+	args = append(args, "req *"+requestType)
+
+	if api.Remark != "" {
+		_, _ = fmt.Fprintf(buf, "// %s %s\n", methodName, strings.ReplaceAll(api.Remark, "\n", " "))
+	}
+	_, _ = fmt.Fprintf(buf, "func (c *%s) %s(%s) (%s, error) {\n", clientName, methodName, strings.Join(args, ", "), responseType)
+	_, _ = fmt.Fprintf(buf, "\treturn client.%s[%s, %s](ctx, c.hc, c.baseUrl+%s, req)\n}\n\n", fn, requestType, responseType, strings.Join(urlParts, "+"))
+
+	return nil
+}
+
+func (g *goClientGenerator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+
+		name := t.Name()
+		base, args, generic := strings.Cut(name, "[")
+		if !isExported(base) {
+			return "", fmt.Errorf("type %s.%s is unexported", t.PkgPath(), base)
+		}
+		expr := g.importAlias(t.PkgPath(), "") + "." + base
+		if generic {
+			rewritten, err := g.rewriteTypeArgs(strings.TrimSuffix(args, "]"))
+			if err != nil {
+				return "", err
+			}
+			expr += "[" + rewritten + "]"
+		}
+		return expr, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported anonymous type %s", t.String())
+}
+
+// rewriteTypeArgs 将泛型实参中的完整包路径替换为导入别名
+func (g *goClientGenerator) rewriteTypeArgs(args string) (string, error) {
+	var err error
+	rewritten := qualifiedTypeRegexp.ReplaceAllStringFunc(args, func(s string) string {
+		m := qualifiedTypeRegexp.FindStringSubmatch(s)
+		if !isExported(m[2]) {
+			err = fmt.Errorf("type %s is unexported", s)
+			return s
+		}
+		return g.importAlias(m[1], "") + "." + m[2]
+	})
+	return rewritten, err
+}
+
+func (g *goClientGenerator) importAlias(importPath string, preferred string) string {
+	if alias, ok := g.imports[importPath]; ok {
+		return alias
+	}
+
+	alias := preferred
+	if alias == "" {
+		segments := strings.Split(importPath, "/")
+		alias = segments[len(segments)-1]
+		if len(segments) > 1 && majorVersionRegexp.MatchString(alias) {
+			alias = segments[len(segments)-2]
+		}
+		alias = goIdentifier(strings.TrimPrefix(alias, "go-"))
+	}
+
+	candidate := alias
+	for i := 2; g.aliases[candidate] != ""; i++ {
+		candidate = alias + strconv.Itoa(i)
+	}
+	g.imports[importPath] = candidate
+	g.aliases[candidate] = importPath
+
+	return candidate
+}
+
+func goIdentifier(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+func isExported(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
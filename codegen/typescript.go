@@ -0,0 +1,591 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+type Int64Mode string
+
+const (
+	INT64_MODE_STRING Int64Mode = "string"
+	INT64_MODE_BIGINT Int64Mode = "bigint"
+)
+
+type TypeScriptOptions struct {
+	// Int64Mode 决定 int64 字段在 TS 中的类型，默认 string。
+	// 响应优先通过 JSON.parse 的 source text access 取得数字原文，不支持的运行时先把数字字面量改写为字符串再解析，不丢失精度
+	Int64Mode Int64Mode
+	// Headers 为允许随请求发送的标准请求头，默认 middleware.AllowHeaders
+	Headers []string
+}
+
+func GenerateTypeScriptFromRequestApis(apis []*wrapper.RequestApi, opts *TypeScriptOptions) ([]byte, error) {
+	return GenerateTypeScript(wrapper.BuildOpenApi(nil, apis), opts)
+}
+
+// ParseOpenApiDoc 解析 OpenAPI 文档，数字按原文保留，避免 int64 枚举值经过 float64 丢失精度
+func ParseOpenApiDoc(data []byte) (*wrapper.OpenApiDoc, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	doc := &wrapper.OpenApiDoc{}
+	if err := decoder.Decode(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func GenerateTypeScript(doc *wrapper.OpenApiDoc, opts *TypeScriptOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &TypeScriptOptions{}
+	}
+	if opts.Int64Mode == "" {
+		opts.Int64Mode = INT64_MODE_STRING
+	}
+	if opts.Int64Mode != INT64_MODE_STRING && opts.Int64Mode != INT64_MODE_BIGINT {
+		return nil, fmt.Errorf("unsupported int64 mode: %s", opts.Int64Mode)
+	}
+	if len(opts.Headers) == 0 {
+		opts.Headers = middleware.AllowHeaders
+	}
+
+	g := &tsGenerator{doc: doc, opts: opts, int64Memo: map[string]bool{}}
+	if doc.Components != nil {
+		g.schemas = doc.Components.Schemas
+	}
+
+	g.writeHeader()
+	g.writeInterfaces()
+	g.writeRuntime()
+	if err := g.writeRoutes(); err != nil {
+		return nil, err
+	}
+
+	return g.buf.Bytes(), nil
+}
+
+type tsGenerator struct {
+	doc       *wrapper.OpenApiDoc
+	opts      *TypeScriptOptions
+	schemas   map[string]*wrapper.OpenApiSchema
+	int64Memo map[string]bool
+	buf       bytes.Buffer
+}
+
+func (g *tsGenerator) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *tsGenerator) writeHeader() {
+	g.printf("// Code generated by go-web codegen. DO NOT EDIT.\n\n")
+	g.printf("export type Int64 = %s;\n\n", g.opts.Int64Mode)
+
+	g.printf("export const standardHeaderNames = [\n")
+	for _, h := range g.opts.Headers {
+		g.printf("  %s,\n", strconv.Quote(h))
+	}
+	g.printf("] as const;\n\n")
+	g.printf("export type StandardHeaderName = (typeof standardHeaderNames)[number];\n")
+	g.printf("export type StandardHeaders = Partial<Record<StandardHeaderName, string>>;\n\n")
+
+	g.printf(`export interface ClientConfig {
+  baseUrl?: string;
+  headers?: StandardHeaders | (() => StandardHeaders);
+  fetch?: typeof fetch;
+}
+
+export const clientConfig: ClientConfig = {};
+
+export function configure(config: ClientConfig): void {
+  Object.assign(clientConfig, config);
+}
+
+export class ApiError extends Error {
+  constructor(public status: number, public body: string) {
+    super('HTTP ' + status);
+  }
+}
+
+export type RequestOptions = Omit<RequestInit, 'method' | 'body' | 'headers'>;
+
+`)
+}
+
+func (g *tsGenerator) writeInterfaces() {
+	for _, name := range sortedKeys(g.schemas) {
+		schema := g.schemas[name]
+		g.printf("export interface %s ", tsIdentifier(name))
+		g.writeObject(schema, "")
+		g.printf("\n\n")
+	}
+}
+
+func (g *tsGenerator) writeObject(schema *wrapper.OpenApiSchema, indent string) {
+	if len(schema.Properties) == 0 {
+		if schema.AdditionalProperties != nil {
+			g.printf("{\n%s  [key: string]: %s;\n%s}", indent, g.tsType(schema.AdditionalProperties, indent+"  "), indent)
+			return
+		}
+		g.printf("{}")
+		return
+	}
+
+	required := map[string]bool{}
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+
+	g.printf("{\n")
+	for _, prop := range sortedKeys(schema.Properties) {
+		property := schema.Properties[prop]
+		if property.Description != "" {
+			g.printf("%s  /** %s */\n", indent, tsComment(property.Description))
+		}
+		optional := "?"
+		if required[prop] {
+			optional = ""
+		}
+		g.printf("%s  %s%s: %s;\n", indent, tsPropertyName(prop), optional, g.tsType(property, indent+"  "))
+	}
+	g.printf("%s}", indent)
+}
+
+func (g *tsGenerator) tsType(schema *wrapper.OpenApiSchema, indent string) string {
+	if schema == nil {
+		return "any"
+	}
+	if schema.Ref != "" {
+		return tsIdentifier(refName(schema.Ref))
+	}
+	if len(schema.Enum) > 0 {
+		literals := make([]string, 0, len(schema.Enum))
+		int64Enum := schema.Type == "integer" && schema.Format == "int64"
+		for _, e := range schema.Enum {
+			if s, ok := e.(string); ok {
+				literals = append(literals, strconv.Quote(s))
+			} else if int64Enum && g.opts.Int64Mode == INT64_MODE_BIGINT {
+				literals = append(literals, fmt.Sprint(e)+"n")
+			} else if int64Enum {
+				// 与 Int64 类型一致，运行时 int64 字段转换为字符串
+				literals = append(literals, strconv.Quote(fmt.Sprint(e)))
+			} else {
+				literals = append(literals, fmt.Sprint(e))
+			}
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "boolean":
+		return "boolean"
+	case "integer":
+		if schema.Format == "int64" {
+			return "Int64"
+		}
+		return "number"
+	case "number":
+		return "number"
+	case "array":
+		item := g.tsType(schema.Items, indent)
+		if strings.ContainsAny(item, " |") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(schema.Properties) == 0 && schema.AdditionalProperties != nil {
+			return "Record<string, " + g.tsType(schema.AdditionalProperties, indent) + ">"
+		}
+		inner := &tsGenerator{opts: g.opts, schemas: g.schemas, int64Memo: g.int64Memo}
+		inner.writeObject(schema, indent)
+		return inner.buf.String()
+	default:
+		return "any"
+	}
+}
+
+// int64Spec 生成 int64 字段位置描述，运行时据此在 JSON 文本层面完成 int64 与字符串/bigint 的转换
+func (g *tsGenerator) int64Spec(schema *wrapper.OpenApiSchema) string {
+	if schema == nil {
+		return ""
+	}
+	if schema.Ref != "" {
+		name := refName(schema.Ref)
+		if g.componentHasInt64(name, map[string]bool{}) {
+			return strconv.Quote(name)
+		}
+		return ""
+	}
+
+	switch schema.Type {
+	case "integer":
+		if schema.Format == "int64" {
+			return "true"
+		}
+	case "array":
+		return g.int64Spec(schema.Items)
+	case "object":
+		var parts []string
+		for _, prop := range sortedKeys(schema.Properties) {
+			if spec := g.int64Spec(schema.Properties[prop]); spec != "" {
+				parts = append(parts, strconv.Quote(prop)+": "+spec)
+			}
+		}
+		if schema.AdditionalProperties != nil {
+			if spec := g.int64Spec(schema.AdditionalProperties); spec != "" {
+				parts = append(parts, `"*": `+spec)
+			}
+		}
+		if len(parts) > 0 {
+			return "{ " + strings.Join(parts, ", ") + " }"
+		}
+	}
+	return ""
+}
+
+func (g *tsGenerator) componentHasInt64(name string, visiting map[string]bool) bool {
+	if has, ok := g.int64Memo[name]; ok {
+		return has
+	}
+	if visiting[name] {
+		return false
+	}
+	visiting[name] = true
+
+	has := schemaHasInt64(g.schemas[name], func(ref string) bool { return g.componentHasInt64(ref, visiting) })
+	delete(visiting, name)
+	if has || len(visiting) == 0 {
+		g.int64Memo[name] = has
+	}
+	return has
+}
+
+func schemaHasInt64(schema *wrapper.OpenApiSchema, refHasInt64 func(ref string) bool) bool {
+	if schema == nil {
+		return false
+	}
+	if schema.Ref != "" {
+		return refHasInt64(refName(schema.Ref))
+	}
+	if schema.Type == "integer" && schema.Format == "int64" {
+		return true
+	}
+	if schemaHasInt64(schema.Items, refHasInt64) || schemaHasInt64(schema.AdditionalProperties, refHasInt64) {
+		return true
+	}
+	for _, property := range schema.Properties {
+		if schemaHasInt64(property, refHasInt64) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *tsGenerator) writeRuntime() {
+	g.printf("type Int64Spec = true | string | { [key: string]: Int64Spec };\n\n")
+	g.printf("const int64Specs: Record<string, Int64Spec> = {\n")
+	for _, name := range sortedKeys(g.schemas) {
+		if spec := g.int64Spec(g.schemas[name]); spec != "" {
+			g.printf("  %s: %s,\n", strconv.Quote(name), spec)
+		}
+	}
+	g.printf("};\n\n")
+
+	toInt64 := "v.raw"
+	if g.opts.Int64Mode == INT64_MODE_BIGINT {
+		toInt64 = "BigInt(v.raw)"
+	}
+
+	g.printf(`function resolveSpec(spec?: Int64Spec): Int64Spec | undefined {
+  return typeof spec === 'string' ? int64Specs[spec] : spec;
+}
+
+function childSpec(spec: Int64Spec | undefined, key: string): Int64Spec | undefined {
+  return typeof spec === 'object' ? spec[key] ?? spec['*'] : undefined;
+}
+
+class RawNumber {
+  constructor(public raw: string) {}
+}
+
+function restoreJson(v: any, spec?: Int64Spec): any {
+  const s = resolveSpec(spec);
+  if (v instanceof RawNumber) return s === true && /^-?\d+$/.test(v.raw) ? %s : Number(v.raw);
+  if (Array.isArray(v)) return v.map((e) => restoreJson(e, s));
+  if (v !== null && typeof v === 'object') {
+    for (const k of Object.keys(v)) v[k] = restoreJson(v[k], childSpec(s, k));
+  }
+  return v;
+}
+
+const sourceTextAccess = (() => {
+  let ok = false;
+  JSON.parse('1', (_key: string, value: any, context?: { source?: string }) => ((ok = context?.source === '1'), value));
+  return ok;
+})();
+
+const RAW_NUMBER_PREFIX = '\u0000raw:';
+
+// quoteNumbers 把字符串之外的数字字面量改写为带前缀的字符串，供不支持 source text access 的运行时保留原文
+function quoteNumbers(text: string): string {
+  const parts: string[] = [];
+  let start = 0;
+  let i = 0;
+  while (i < text.length) {
+    const c = text[i];
+    if (c === '"') {
+      for (i++; i < text.length && text[i] !== '"'; i += text[i] === '\\' ? 2 : 1);
+      i++;
+    } else if (c === '-' || (c >= '0' && c <= '9')) {
+      let j = i + 1;
+      while (j < text.length && /[0-9eE.+-]/.test(text[j])) j++;
+      parts.push(text.slice(start, i), JSON.stringify(RAW_NUMBER_PREFIX + text.slice(i, j)));
+      start = i = j;
+    } else {
+      i++;
+    }
+  }
+  parts.push(text.slice(start));
+  return parts.join('');
+}
+
+function parseJson(text: string, spec?: Int64Spec): any {
+  if (!text) return undefined;
+  const v = sourceTextAccess
+    ? JSON.parse(text, (_key: string, value: any, context?: { source?: string }) =>
+        typeof value === 'number' ? new RawNumber(context?.source ?? String(value)) : value,
+      )
+    : JSON.parse(quoteNumbers(text), (_key: string, value: any) =>
+        typeof value === 'string' && value.startsWith(RAW_NUMBER_PREFIX) ? new RawNumber(value.slice(RAW_NUMBER_PREFIX.length)) : value,
+      );
+  return restoreJson(v, spec);
+}
+
+function stringifyJson(v: any, spec?: Int64Spec): string {
+  const s = resolveSpec(spec);
+  if (v === undefined || v === null) return 'null';
+  if (typeof v === 'bigint') return String(v);
+  if (s === true && typeof v === 'string' && /^-?\d+$/.test(v)) return v;
+  if (Array.isArray(v)) return '[' + v.map((e) => stringifyJson(e, s)).join(',') + ']';
+  if (typeof v === 'object' && !(v instanceof Date)) {
+    const parts: string[] = [];
+    for (const k of Object.keys(v)) {
+      if (v[k] !== undefined) parts.push(JSON.stringify(k) + ':' + stringifyJson(v[k], childSpec(s, k)));
+    }
+    return '{' + parts.join(',') + '}';
+  }
+  return JSON.stringify(v);
+}
+
+async function request<T>(
+  method: string,
+  path: string,
+  query: Record<string, any> | undefined,
+  routeHeaders: Record<string, any> | undefined,
+  body: any,
+  bodySpec: Int64Spec | undefined,
+  resultSpec: Int64Spec | undefined,
+  options?: RequestOptions,
+): Promise<T> {
+  let url = (clientConfig.baseUrl ?? '') + path;
+  if (query) {
+    const qs = new URLSearchParams();
+    for (const k of Object.keys(query)) {
+      const v = query[k];
+      if (v === undefined || v === null) continue;
+      for (const e of Array.isArray(v) ? v : [v]) qs.append(k, String(e));
+    }
+    const s = qs.toString();
+    if (s) url += '?' + s;
+  }
+
+  const headers: Record<string, string> = {};
+  const std = typeof clientConfig.headers === 'function' ? clientConfig.headers() : clientConfig.headers ?? {};
+  for (const name of standardHeaderNames) {
+    const v = std[name];
+    if (v !== undefined && v !== '') headers[name] = v;
+  }
+  for (const k of Object.keys(routeHeaders ?? {})) {
+    const v = routeHeaders![k];
+    if (v !== undefined && v !== null) headers[k] = String(v);
+  }
+  if (body !== undefined) headers['Content-Type'] = 'application/json';
+
+  const resp = await (clientConfig.fetch ?? fetch)(url, {
+    ...options,
+    method,
+    headers,
+    body: body === undefined ? undefined : stringifyJson(body, bodySpec),
+  });
+  const text = await resp.text();
+  if (!resp.ok) throw new ApiError(resp.status, text);
+  return parseJson(text, resultSpec) as T;
+}
+
+`, toInt64)
+}
+
+func (g *tsGenerator) writeRoutes() error {
+	for _, p := range sortedKeys(g.doc.Paths) {
+		item := g.doc.Paths[p]
+		if item.Get != nil {
+			if err := g.writeRoute(http.MethodGet, p, item.Get); err != nil {
+				return err
+			}
+		}
+		if item.Post != nil {
+			if err := g.writeRoute(http.MethodPost, p, item.Post); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *tsGenerator) writeRoute(method string, p string, op *wrapper.OpenApiOperation) error {
+	name := tsIdentifier(op.OperationId)
+	if name == "" {
+		return fmt.Errorf("missing operationId for %s %s", method, p)
+	}
+
+	var pathParams, queryParams, headerParams []*wrapper.OpenApiParameter
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			pathParams = append(pathParams, param)
+		case "query":
+			queryParams = append(queryParams, param)
+		case "header":
+			headerParams = append(headerParams, param)
+		}
+	}
+
+	resultType, resultSpec := "any", "undefined"
+	if resp := op.Responses[strconv.Itoa(http.StatusOK)]; resp != nil {
+		if mt := resp.Content["application/json"]; mt != nil {
+			resultType = g.tsType(mt.Schema, "")
+			if spec := g.int64Spec(mt.Schema); spec != "" {
+				resultSpec = spec
+			}
+		}
+	}
+
+	var args []string
+	pathExpr := strconv.Quote(p)
+	if len(pathParams) > 0 {
+		var fields []string
+		for _, param := range pathParams {
+			fields = append(fields, tsPropertyName(param.Name)+": string | number")
+		}
+		args = append(args, "path: { "+strings.Join(fields, "; ")+" }")
+		pathExpr = "`" + p + "`"
+		for _, param := range pathParams {
+			pathExpr = strings.ReplaceAll(pathExpr, "{"+param.Name+"}", "${encodeURIComponent(String(path["+strconv.Quote(param.Name)+"]))}")
+		}
+	}
+
+	queryExpr, headersExpr, bodyExpr, bodySpec := "undefined", "undefined", "undefined", "undefined"
+	if len(queryParams) > 0 {
+		paramsName := tsIdentifier(strings.ToUpper(name[:1]) + name[1:] + "Params")
+		g.printf("export interface %s {\n", paramsName)
+		for _, param := range queryParams {
+			if param.Description != "" {
+				g.printf("  /** %s */\n", tsComment(param.Description))
+			}
+			optional := "?"
+			if param.Required {
+				optional = ""
+			}
+			g.printf("  %s%s: %s;\n", tsPropertyName(param.Name), optional, g.tsType(param.Schema, "  "))
+		}
+		g.printf("}\n\n")
+		args = append(args, "params: "+paramsName)
+		queryExpr = "params"
+	}
+	var headersArg string
+	if len(headerParams) > 0 {
+		var fields []string
+		optional := true
+		for _, param := range headerParams {
+			if param.Required {
+				fields = append(fields, tsPropertyName(param.Name)+": string | number")
+				optional = false
+			} else {
+				fields = append(fields, tsPropertyName(param.Name)+"?: string | number")
+			}
+		}
+		headersArg = "headers: { " + strings.Join(fields, "; ") + " }"
+		if optional {
+			headersArg = "headers?: { " + strings.Join(fields, "; ") + " }"
+		}
+		headersExpr = "headers"
+	}
+	if op.RequestBody != nil {
+		if mt := op.RequestBody.Content["application/json"]; mt != nil {
+			args = append(args, "body: "+g.tsType(mt.Schema, ""))
+			bodyExpr = "body"
+			if spec := g.int64Spec(mt.Schema); spec != "" {
+				bodySpec = spec
+			}
+		}
+	}
+	if headersArg != "" {
+		args = append(args, headersArg)
+	}
+	args = append(args, "options?: RequestOptions")
+
+	if op.Summary != "" {
+		g.printf("/** %s */\n", tsComment(op.Summary))
+	}
+	g.printf("export function %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), resultType)
+	g.printf("  return request<%s>(%s, %s, %s, %s, %s, %s, %s, options);\n", resultType, strconv.Quote(method), pathExpr, queryExpr, headersExpr, bodyExpr, bodySpec, resultSpec)
+	g.printf("}\n\n")
+
+	return nil
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func tsIdentifier(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+func tsPropertyName(name string) string {
+	if name != "" && tsIdentifier(name) == name {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func tsComment(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "*/", "* /"), "\n", " ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	dgerr "github.com/darwinOrg/go-common/enums/error"
 	"github.com/darwinOrg/go-common/result"
@@ -29,9 +30,12 @@ func CopyBody() gin.HandlerFunc {
 
 func CopyBodyWithConfig(config CopyBodyConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// multipart 请求的上传文件在绑定时流式读取，tus 分片等二进制请求体由处理器直接读取，都不整体读入内存
 		if !AllowedPathPrefixes(c, config.AllowedPathPrefixes...) ||
 			SkippedPathPrefixes(c, config.SkippedPathPrefixes...) ||
-			c.Request.Body == nil {
+			c.Request.Body == nil ||
+			c.ContentType() == gin.MIMEMultipartPOSTForm ||
+			isBinaryContentType(c.ContentType()) {
 			c.Next()
 			return
 		}
@@ -69,3 +73,11 @@ func CopyBodyWithConfig(config CopyBodyConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// isBinaryContentType application/octet-stream、application/offset+octet-stream（tus）以及图片、音视频等二进制请求体
+func isBinaryContentType(contentType string) bool {
+	return strings.HasSuffix(contentType, "octet-stream") ||
+		strings.HasPrefix(contentType, "image/") ||
+		strings.HasPrefix(contentType, "audio/") ||
+		strings.HasPrefix(contentType, "video/")
+}
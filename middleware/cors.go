@@ -51,12 +51,33 @@ var AllowHeaders = []string{
 	"X-Forwarded-For",
 	"X-Forwarded-Proto",
 	"Authorization",
+
+	// tus 断点续传
+	"Tus-Resumable",
+	"Upload-Length",
+	"Upload-Offset",
+	"Upload-Metadata",
+	"Upload-Defer-Length",
+}
+
+// ExposeHeaders 浏览器中的脚本可以读取的响应头，tus 客户端依赖它们获取上传地址和进度
+var ExposeHeaders = []string{
+	"Location",
+	"Upload-Offset",
+	"Upload-Length",
+	"Upload-Metadata",
+	"Upload-Expires",
+	"Tus-Resumable",
+	"Tus-Version",
+	"Tus-Extension",
+	"Tus-Max-Size",
 }
 
 func Cors() gin.HandlerFunc {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = AllowOrigins
 	corsConfig.AllowHeaders = AllowHeaders
+	corsConfig.ExposeHeaders = ExposeHeaders
 
 	return cors.New(corsConfig)
 }
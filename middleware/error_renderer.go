@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	errorRendererKey = "ErrorRenderer"
+	bindErrorKey     = "BindError"
+)
+
+// ErrorRenderer 输出登录、权限、参数校验、panic 等框架层面的失败结果，rt 为 result.Result 形式的失败结果
+type ErrorRenderer interface {
+	RenderError(c *gin.Context, rt any)
+}
+
+type ErrorRendererFunc func(c *gin.Context, rt any)
+
+func (f ErrorRendererFunc) RenderError(c *gin.Context, rt any) {
+	f(c, rt)
+}
+
+// ResultErrorRenderer 默认的渲染方式，以 JSON 输出 result.Result
+type ResultErrorRenderer struct{}
+
+func (ResultErrorRenderer) RenderError(c *gin.Context, rt any) {
+	c.AbortWithStatusJSON(ResultStatus(c, rt), rt)
+}
+
+var DefaultErrorRenderer ErrorRenderer = ResultErrorRenderer{}
+
+// UseErrorRenderer 为 engine、group 或单个路由指定错误渲染方式，覆盖 DefaultErrorRenderer
+func UseErrorRenderer(renderer ErrorRenderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(errorRendererKey, renderer)
+		c.Next()
+	}
+}
+
+func GetErrorRenderer(c *gin.Context) ErrorRenderer {
+	if renderer, ok := c.Get(errorRendererKey); ok {
+		if er, ok := renderer.(ErrorRenderer); ok {
+			return er
+		}
+	}
+	return DefaultErrorRenderer
+}
+
+// AbortWithResult 中止请求并使用当前的 ErrorRenderer 输出失败结果
+func AbortWithResult(c *gin.Context, rt any) {
+	GetErrorRenderer(c).RenderError(c, rt)
+	c.Abort()
+}
+
+// SetBindError 记录参数绑定或校验的错误，供 ErrorRenderer 输出字段级的错误信息
+func SetBindError(c *gin.Context, err error) {
+	c.Set(bindErrorKey, err)
+}
+
+func GetBindError(c *gin.Context) error {
+	if err, ok := c.Get(bindErrorKey); ok {
+		if e, ok := err.(error); ok {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"encoding/json"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dglogger "github.com/darwinOrg/go-logger"
+	"go.uber.org/zap"
+)
+
+type LogFormat int
+
+const (
+	LOG_FORMAT_TEXT   LogFormat = 0
+	LOG_FORMAT_FIELDS LogFormat = 1
+)
+
+// DefaultLogFormat 请求日志和业务日志的输出格式，TEXT 保持原有的格式化字符串，
+// FIELDS 按字段输出，JSON 或 console 编码由 go-logger 的配置决定
+var DefaultLogFormat = LOG_FORMAT_TEXT
+
+// StructuredLogger 按字段输出一条 Info 日志
+type StructuredLogger func(ctx *dgctx.DgContext, msg string, fields ...zap.Field)
+
+// DefaultStructuredLogger FIELDS 格式的请求日志和业务日志通过它输出，默认交给 dglogger
+var DefaultStructuredLogger StructuredLogger = func(ctx *dgctx.DgContext, msg string, fields ...zap.Field) {
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		args[i] = f
+	}
+	dglogger.Infow(ctx, msg, args...)
+}
+
+// LogStructured 以结构化字段输出一条 Info 日志
+func LogStructured(ctx *dgctx.DgContext, msg string, fields LogFields) {
+	DefaultStructuredLogger(ctx, msg, fields.ZapFields()...)
+}
+
+type LogField struct {
+	Key   string
+	Value any
+}
+
+// LogFields 有序的日志字段，值为 json.RawMessage 时按原始 JSON 输出
+type LogFields []LogField
+
+func (fs LogFields) Add(key string, value any) LogFields {
+	return append(fs, LogField{Key: key, Value: value})
+}
+
+// ZapFields 转换为 zap 的字段，合法的 json.RawMessage 由编码器原样输出
+func (fs LogFields) ZapFields() []zap.Field {
+	fields := make([]zap.Field, 0, len(fs))
+	for _, f := range fs {
+		if raw, ok := f.Value.(json.RawMessage); ok {
+			if json.Valid(raw) {
+				fields = append(fields, zap.Reflect(f.Key, raw))
+			} else {
+				fields = append(fields, zap.String(f.Key, string(raw)))
+			}
+			continue
+		}
+		fields = append(fields, zap.Any(f.Key, logFieldValue(f.Value)))
+	}
+	return fields
+}
+
+func logFieldValue(v any) any {
+	switch val := v.(type) {
+	case time.Duration:
+		return val.String()
+	case error:
+		return val.Error()
+	default:
+		return v
+	}
+}
+
+// DurationMillis 耗时的毫秒数，保留三位小数
+func DurationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
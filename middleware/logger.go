@@ -4,20 +4,34 @@ import (
 	"fmt"
 	"time"
 
+	dgctx "github.com/darwinOrg/go-common/context"
 	dglogger "github.com/darwinOrg/go-logger"
 	"github.com/darwinOrg/go-web/utils"
 	"github.com/gin-gonic/gin"
 )
 
+const routeKey = "LogRoute"
+
 // Logger instances a Logger middleware that will write the logs to gin.DefaultWriter.
 // By default, gin.DefaultWriter = os.Stdout.
 func Logger() gin.HandlerFunc {
-	return LoggerWithConfig(gin.LoggerConfig{Formatter: customLogFormatter})
+	return LoggerWithConfig(gin.LoggerConfig{})
+}
+
+// LoggerWithFormat FIELDS 通过 dglogger 按字段输出请求日志，TEXT 与 Logger 相同
+func LoggerWithFormat(format LogFormat, skipPaths ...string) gin.HandlerFunc {
+	return loggerWithConfig(gin.LoggerConfig{SkipPaths: skipPaths}, format)
 }
 
 // LoggerWithConfig instance a Logger middleware with config.
+// 没有指定 Formatter 时使用 DefaultLogFormat 格式。
 func LoggerWithConfig(conf gin.LoggerConfig) gin.HandlerFunc {
+	return loggerWithConfig(conf, DefaultLogFormat)
+}
+
+func loggerWithConfig(conf gin.LoggerConfig, format LogFormat) gin.HandlerFunc {
 	formatter := conf.Formatter
+	structured := formatter == nil && format != LOG_FORMAT_TEXT
 	if formatter == nil {
 		formatter = customLogFormatter
 	}
@@ -43,6 +57,7 @@ func LoggerWithConfig(conf gin.LoggerConfig) gin.HandlerFunc {
 
 		// Log only when path is not being skipped
 		if _, ok := skip[path]; !ok {
+			c.Set(routeKey, c.FullPath())
 			param := gin.LogFormatterParams{
 				Request: c.Request,
 				Keys:    c.Keys,
@@ -64,11 +79,35 @@ func LoggerWithConfig(conf gin.LoggerConfig) gin.HandlerFunc {
 			}
 			param.Path = path
 
-			dglogger.Infoln(utils.GetDgContext(c), formatter(param))
+			if structured {
+				LogStructured(utils.GetDgContext(c), "request", requestLogFields(param))
+			} else {
+				dglogger.Infoln(utils.GetDgContext(c), formatter(param))
+			}
 		}
 	}
 }
 
+func requestLogFields(param gin.LogFormatterParams) LogFields {
+	fields := LogFields{}.
+		Add("path", param.Path).
+		Add("method", param.Method).
+		Add("status", param.StatusCode).
+		Add("clientIp", param.ClientIP).
+		Add("costMs", DurationMillis(param.Latency)).
+		Add("bodySize", param.BodySize)
+	if route, ok := param.Keys[routeKey].(string); ok && route != "" {
+		fields = fields.Add("route", route)
+	}
+	if ctx, ok := param.Keys[utils.DgContextKey].(*dgctx.DgContext); ok {
+		fields = fields.Add("traceId", ctx.TraceId).Add("userId", ctx.UserId).Add("companyId", ctx.CompanyId)
+	}
+	if param.ErrorMessage != "" {
+		fields = fields.Add("error", param.ErrorMessage)
+	}
+	return fields
+}
+
 // customLogFormatter is the custom log format function Logger middleware uses.
 var customLogFormatter = func(param gin.LogFormatterParams) string {
 	var statusColor, methodColor, resetColor string
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem RFC 9457 定义的错误响应，Code、TraceId、Errors 为扩展字段
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     int                 `json:"code"`
+	TraceId  string              `json:"traceId,omitempty"`
+	Errors   []*utils.FieldError `json:"errors,omitempty"`
+}
+
+type ProblemType struct {
+	Type   string // 相对路径时拼接在 ProblemErrorRenderer.BaseUri 之后
+	Title  string
+	Status int
+}
+
+var (
+	ProblemDefaultStatus = http.StatusBadRequest
+	problemCatalog       = map[int]*ProblemType{
+		dgerr.ARGUMENT_NOT_VALID.Code: {Type: "invalid-argument", Title: "Invalid Argument", Status: http.StatusBadRequest},
+		dgerr.NOT_LOGIN_IN.Code:       {Type: "not-login", Title: "Not Logged In", Status: http.StatusUnauthorized},
+		dgerr.NO_PERMISSION.Code:      {Type: "no-permission", Title: "No Permission", Status: http.StatusForbidden},
+		dgerr.SYSTEM_ERROR.Code:       {Type: "system-error", Title: "System Error", Status: http.StatusInternalServerError},
+	}
+	validationProblemType = &ProblemType{Type: "validation-error", Title: "Validation Failed", Status: http.StatusBadRequest}
+)
+
+// RegisterProblemType 为错误码注册 problem 类型，同一个错误码会被覆盖
+func RegisterProblemType(code int, pt *ProblemType) {
+	problemCatalog[code] = pt
+}
+
+// ProblemErrorRenderer 以 application/problem+json 输出错误
+type ProblemErrorRenderer struct {
+	BaseUri string // 例如 https://open.example.com/problems/
+}
+
+func (r *ProblemErrorRenderer) RenderError(c *gin.Context, rt any) {
+	problem := r.BuildProblem(c, rt)
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+func (r *ProblemErrorRenderer) BuildProblem(c *gin.Context, rt any) *Problem {
+	ctx := utils.GetDgContext(c)
+	code, _, _ := ResultCode(rt)
+	problem := &Problem{
+		Code:     code,
+		Detail:   resultMessage(rt),
+		Instance: c.Request.URL.Path,
+		TraceId:  ctx.TraceId,
+	}
+
+	pt := problemCatalog[code]
+	if bindErr := GetBindError(c); bindErr != nil {
+		pt = validationProblemType
+		problem.Errors = utils.TranslateFieldErrors(bindErr, ctx.Lang)
+	}
+
+	if pt != nil {
+		problem.Type = r.typeUri(pt.Type)
+		problem.Title = pt.Title
+		problem.Status = pt.Status
+	} else {
+		problem.Type = "about:blank"
+	}
+
+	if status := c.GetInt(statusHintKey); status > 0 {
+		problem.Status = status
+	}
+	if problem.Status == 0 {
+		if status, ok := RestStatusTable[code]; ok {
+			problem.Status = status
+		} else if code >= minErrorStatus && code <= maxErrorStatus {
+			problem.Status = code
+		} else {
+			problem.Status = ProblemDefaultStatus
+		}
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	return problem
+}
+
+func (r *ProblemErrorRenderer) typeUri(t string) string {
+	if r.BaseUri == "" || strings.Contains(t, ":") {
+		return t
+	}
+	return strings.TrimSuffix(r.BaseUri, "/") + "/" + t
+}
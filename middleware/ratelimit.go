@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/darwinOrg/go-monitor"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitAlgorithm int
+
+const (
+	RATE_LIMIT_TOKEN_BUCKET   RateLimitAlgorithm = 0
+	RATE_LIMIT_SLIDING_WINDOW RateLimitAlgorithm = 1
+)
+
+const rateLimitedCounterName = "server_http_rate_limited_total"
+
+var ErrTooManyRequests = &dgerr.DgError{Code: http.StatusTooManyRequests, Message: "too many requests"}
+
+type RateLimitKeyFunc func(c *gin.Context, ctx *dgctx.DgContext) string
+
+var (
+	RateLimitByUserId RateLimitKeyFunc = func(c *gin.Context, ctx *dgctx.DgContext) string {
+		return strconv.FormatInt(ctx.UserId, 10)
+	}
+	RateLimitByCompanyId RateLimitKeyFunc = func(c *gin.Context, ctx *dgctx.DgContext) string {
+		return strconv.FormatInt(ctx.CompanyId, 10)
+	}
+	RateLimitByClientIP RateLimitKeyFunc = func(c *gin.Context, ctx *dgctx.DgContext) string {
+		return utils.GetClientIP(c)
+	}
+)
+
+type RateLimitConfig struct {
+	// Name 为限流键的前缀，默认使用路由模板，多个路由共享同一个 Name 时共享配额
+	Name      string
+	Limit     int
+	Window    time.Duration
+	Burst     int // 令牌桶容量，默认等于 Limit
+	Algorithm RateLimitAlgorithm
+	KeyFunc   RateLimitKeyFunc // 默认按客户端 IP
+	Store     RateLimitStore   // 默认 DefaultRateLimitStore
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore 保存限流状态，共享存储（如 redis）需要原子地完成一次取令牌或计数
+type RateLimitStore interface {
+	Take(key string, config *RateLimitConfig, now time.Time) (*RateLimitResult, error)
+}
+
+var DefaultRateLimitStore RateLimitStore = NewMemoryRateLimitStore()
+
+func RateLimitWithConfig(config RateLimitConfig) gin.HandlerFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByClientIP
+	}
+	if config.Store == nil {
+		config.Store = DefaultRateLimitStore
+	}
+	if config.Burst <= 0 {
+		config.Burst = config.Limit
+	}
+
+	return func(c *gin.Context) {
+		if config.Limit <= 0 || config.Window <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx := utils.GetDgContext(c)
+		name := config.Name
+		if name == "" {
+			name = c.FullPath()
+		}
+		if name == "" {
+			name = c.Request.URL.Path
+		}
+
+		rt, err := config.Store.Take(name+":"+config.KeyFunc(c, ctx), &config, time.Now())
+		if err != nil {
+			dglogger.Errorf(ctx, "rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(rt.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(rt.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(rt.ResetAfter)))
+
+		if !rt.Allowed {
+			dglogger.Warnf(ctx, "rate limited, name: %s, retry after: %v", name, rt.RetryAfter)
+			_ = monitor.IncCounter(rateLimitedCounterName, map[string]string{"path": name})
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(rt.RetryAfter)))
+			ForceResultStatus(c, http.StatusTooManyRequests)
+			AbortWithResult(c, result.SimpleFailByError(ErrTooManyRequests))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type rateLimitEntry struct {
+	// 所属配置的窗口，清理时按各自的窗口判断是否过期
+	window time.Duration
+	// 令牌桶
+	tokens float64
+	last   time.Time
+	// 滑动窗口
+	windowStart time.Time
+	prevCount   int
+	currCount   int
+}
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastPurge time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{entries: map[string]*rateLimitEntry{}}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, config *RateLimitConfig, now time.Time) (*RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(config.Window, now)
+
+	entry := s.entries[key]
+	if entry == nil {
+		entry = &rateLimitEntry{window: config.Window, tokens: float64(config.Burst), last: now, windowStart: now.Truncate(config.Window)}
+		s.entries[key] = entry
+	}
+
+	if config.Algorithm == RATE_LIMIT_SLIDING_WINDOW {
+		return takeSlidingWindow(entry, config, now), nil
+	}
+	return takeTokenBucket(entry, config, now), nil
+}
+
+// purge 每个窗口最多清理一次长时间未访问的条目，存储被多个路由共享，条目是否过期按其自身的窗口判断
+func (s *MemoryRateLimitStore) purge(window time.Duration, now time.Time) {
+	if now.Sub(s.lastPurge) < window {
+		return
+	}
+	s.lastPurge = now
+
+	for k, e := range s.entries {
+		if now.Sub(e.last) > 2*e.window {
+			delete(s.entries, k)
+		}
+	}
+}
+
+func takeTokenBucket(entry *rateLimitEntry, config *RateLimitConfig, now time.Time) *RateLimitResult {
+	rate := float64(config.Limit) / config.Window.Seconds()
+	capacity := float64(config.Burst)
+
+	entry.tokens = math.Min(capacity, entry.tokens+now.Sub(entry.last).Seconds()*rate)
+	entry.last = now
+
+	rt := &RateLimitResult{Limit: config.Burst}
+	if entry.tokens >= 1 {
+		entry.tokens--
+		rt.Allowed = true
+	} else {
+		rt.RetryAfter = time.Duration((1 - entry.tokens) / rate * float64(time.Second))
+	}
+	rt.Remaining = int(entry.tokens)
+	rt.ResetAfter = time.Duration((capacity - entry.tokens) / rate * float64(time.Second))
+
+	return rt
+}
+
+// takeSlidingWindow 使用滑动窗口计数，按上一个窗口的剩余占比估算当前窗口内的请求数
+func takeSlidingWindow(entry *rateLimitEntry, config *RateLimitConfig, now time.Time) *RateLimitResult {
+	windowStart := now.Truncate(config.Window)
+	if !windowStart.Equal(entry.windowStart) {
+		if windowStart.Sub(entry.windowStart) == config.Window {
+			entry.prevCount = entry.currCount
+		} else {
+			entry.prevCount = 0
+		}
+		entry.currCount = 0
+		entry.windowStart = windowStart
+	}
+	entry.last = now
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(config.Window)
+	estimated := float64(entry.prevCount)*weight + float64(entry.currCount)
+
+	rt := &RateLimitResult{Limit: config.Limit, ResetAfter: config.Window - elapsed}
+	if estimated+1 > float64(config.Limit) {
+		rt.RetryAfter = rt.ResetAfter
+	} else {
+		entry.currCount++
+		estimated++
+		rt.Allowed = true
+	}
+	rt.Remaining = max(config.Limit-int(math.Ceil(estimated)), 0)
+
+	return rt
+}
@@ -30,10 +30,8 @@ func myRecover(c *gin.Context, err any) {
 	ctx := utils.GetDgContext(c)
 	dglogger.Errorf(ctx, "panic error: %v", err)
 
-	// 封装通用json结果返回
-	c.JSON(http.StatusOK, errorToResult(c, ctx, err))
-	// 终止后续接口调用，不加的话recover到异常后，还会继续执行接口里后续代码
-	c.Abort()
+	// 封装通用结果返回，并终止后续接口调用，不加的话recover到异常后，还会继续执行接口里后续代码
+	AbortWithResult(c, errorToResult(c, ctx, err))
 }
 
 func errorToResult(c *gin.Context, ctx *dgctx.DgContext, r any) any {
@@ -43,12 +41,14 @@ func errorToResult(c *gin.Context, ctx *dgctx.DgContext, r any) any {
 		return result.FailByError[*dgerr.DgError](r.(*dgerr.DgError))
 	case error:
 		processRecoverError(c, ctx, r.(error))
+		SetResultStatusHint(c, http.StatusInternalServerError)
 		if dgsys.IsProd() && !isInternalRequest(c) {
 			return result.SimpleFailByError(dgerr.SYSTEM_ERROR)
 		}
 		return result.SimpleFailByError(r.(error))
 	case string:
 		processRecoverError(c, ctx, errors.New(r.(string)))
+		SetResultStatusHint(c, http.StatusInternalServerError)
 		return result.SimpleFail[string](r.(string))
 	default:
 		processRecoverError(c, ctx, dgerr.SYSTEM_ERROR)
@@ -56,6 +56,21 @@ func errorToResult(c *gin.Context, ctx *dgctx.DgContext, r any) any {
 	}
 }
 
+// ReportPanic 记录响应已经返回后才发生的 panic 及其调用栈，并执行注册的 RecoverProcessor，不再写入响应。
+// 用于超时后被放弃、仍在执行的 BizHandler
+func ReportPanic(c *gin.Context, ctx *dgctx.DgContext, r any, stack []byte) {
+	dglogger.Errorf(ctx, "panic error after response: %v\n%s", r, stack)
+
+	switch e := r.(type) {
+	case error:
+		processRecoverError(c, ctx, e)
+	case string:
+		processRecoverError(c, ctx, errors.New(e))
+	default:
+		processRecoverError(c, ctx, dgerr.SYSTEM_ERROR)
+	}
+}
+
 func isInternalRequest(c *gin.Context) bool {
 	return strings.Split(c.Request.URL.Path, "/")[0] == "internal"
 }
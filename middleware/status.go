@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"reflect"
+
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	restStatusKey   = "RestStatus"
+	statusHintKey   = "ResultStatusHint"
+	statusForcedKey = "ResultStatusForced"
+	minErrorStatus  = http.StatusBadRequest
+	maxErrorStatus  = 599
+)
+
+// RestStatusTable 开启 REST 状态码模式后，失败结果的 code 到 HTTP 状态码的映射
+var RestStatusTable = map[int]int{
+	dgerr.ARGUMENT_NOT_VALID.Code: http.StatusBadRequest,
+	dgerr.NOT_LOGIN_IN.Code:       http.StatusUnauthorized,
+	dgerr.NO_PERMISSION.Code:      http.StatusForbidden,
+	dgerr.SYSTEM_ERROR.Code:       http.StatusInternalServerError,
+}
+
+// RestUnmappedFailStatus 没有映射且 code 不是 HTTP 错误码的失败结果（通常是业务错误）使用的状态码
+var RestUnmappedFailStatus = http.StatusOK
+
+// RestStatus 开启 REST 状态码模式，可以在 engine、group 上使用，也可以通过 RequestHolder.RestStatus 按路由开启，
+// 响应体保持不变，只改变失败结果的 HTTP 状态码
+func RestStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(restStatusKey, true)
+		c.Next()
+	}
+}
+
+func IsRestStatus(c *gin.Context) bool {
+	return c.GetBool(restStatusKey)
+}
+
+// SetResultStatusHint 为当前请求的失败结果指定 REST 模式下的状态码，优先于 RestStatusTable，例如参数校验失败时使用 400
+func SetResultStatusHint(c *gin.Context, status int) {
+	c.Set(statusHintKey, status)
+}
+
+// ForceResultStatus 为当前请求的失败结果指定状态码，不论是否开启 REST 模式，例如限流时始终使用 429
+func ForceResultStatus(c *gin.Context, status int) {
+	c.Set(statusHintKey, status)
+	c.Set(statusForcedKey, true)
+}
+
+// ResultStatus 返回结果对应的 HTTP 状态码，未开启 REST 模式或结果成功时为 200
+func ResultStatus(c *gin.Context, rt any) int {
+	if c.GetBool(statusForcedKey) {
+		if _, success, ok := ResultCode(rt); ok && !success {
+			return c.GetInt(statusHintKey)
+		}
+	}
+	if !IsRestStatus(c) {
+		return http.StatusOK
+	}
+
+	code, success, ok := ResultCode(rt)
+	if !ok || success {
+		return http.StatusOK
+	}
+
+	if status := c.GetInt(statusHintKey); status > 0 {
+		return status
+	}
+	if status, ok := RestStatusTable[code]; ok {
+		return status
+	}
+	if code >= minErrorStatus && code <= maxErrorStatus {
+		return code
+	}
+	return RestUnmappedFailStatus
+}
+
+// ResultCode 通过反射读取 result.Result 的 Code 和 Success 字段
+func ResultCode(rt any) (code int, success bool, ok bool) {
+	v := reflect.ValueOf(rt)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0, false, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false, false
+	}
+
+	sf := v.FieldByName("Success")
+	cf := v.FieldByName("Code")
+	if !sf.IsValid() || sf.Kind() != reflect.Bool || !cf.IsValid() || !cf.CanInt() {
+		return 0, false, false
+	}
+	return int(cf.Int()), sf.Bool(), true
+}
+
+func resultMessage(rt any) string {
+	v := reflect.ValueOf(rt)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	if f := v.FieldByName("Message"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
@@ -0,0 +1,333 @@
+package rbac
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darwinOrg/go-common/result"
+	"github.com/gin-gonic/gin"
+)
+
+type Engine struct {
+	policy atomic.Pointer[compiledPolicy]
+	// RolesResolver 用于调试接口根据 uid 查询角色
+	RolesResolver func(uid int64) ([]string, error)
+
+	routesMu sync.RWMutex
+	routes   []*Route
+}
+
+// Route 注册的路由及其声明的 AllowRoles，Path 为路由模板，如 /order/:id/cancel
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	AllowRoles []string `json:"allowRoles,omitempty"`
+}
+
+type compiledPolicy struct {
+	policy   *Policy
+	inherits map[string][]string
+}
+
+type Decision struct {
+	Allowed        bool     `json:"allowed"`
+	Method         string   `json:"method"`
+	Path           string   `json:"path"`
+	Roles          []string `json:"roles"`
+	EffectiveRoles []string `json:"effectiveRoles"`
+	Route          string   `json:"route,omitempty"`
+	MatchedRules   []string `json:"matchedRules,omitempty"`
+	Reasons        []string `json:"reasons"`
+}
+
+func NewEngine() *Engine {
+	e := &Engine{}
+	e.policy.Store(compile(&Policy{}))
+	return e
+}
+
+func NewEngineWithPolicy(policy *Policy) (*Engine, error) {
+	e := NewEngine()
+	if err := e.Load(policy); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Load 校验并原子替换当前策略，正在处理的请求不受影响
+func (e *Engine) Load(policy *Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	e.policy.Store(compile(policy))
+	return nil
+}
+
+func (e *Engine) LoadFile(file string) error {
+	policy, err := LoadPolicyFile(file)
+	if err != nil {
+		return err
+	}
+	e.policy.Store(compile(policy))
+	return nil
+}
+
+func (e *Engine) Policy() *Policy {
+	return e.policy.Load().policy
+}
+
+// WatchFile 定期检查策略文件的修改时间并热加载，加载失败时保留原有策略
+func (e *Engine) WatchFile(file string, interval time.Duration) (stop func(), err error) {
+	if err = e.LoadFile(file); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	modTime := info.ModTime()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				info, serr := os.Stat(file)
+				if serr != nil || !info.ModTime().After(modTime) {
+					continue
+				}
+				modTime = info.ModTime()
+				if lerr := e.LoadFile(file); lerr != nil {
+					log.Printf("reload rbac policy %s error: %v", file, lerr)
+				} else {
+					log.Printf("reload rbac policy %s success", file)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
+
+// Evaluate 依次检查拒绝规则、路由声明的 allowRoles 和匹配到的允许规则，任一拒绝即拒绝
+func (e *Engine) Evaluate(roles []string, method string, path string, allowRoles []string) *Decision {
+	cp := e.policy.Load()
+	effective := cp.expand(roles)
+	d := &Decision{
+		Allowed:        true,
+		Method:         method,
+		Path:           path,
+		Roles:          roles,
+		EffectiveRoles: sortedRoles(effective),
+	}
+
+	var matched []*Rule
+	for _, rule := range cp.policy.Rules {
+		if rule.matches(method, path) {
+			matched = append(matched, rule)
+			d.MatchedRules = append(d.MatchedRules, rule.describe())
+		}
+	}
+
+	for _, rule := range matched {
+		if denied := intersect(effective, rule.Deny); len(denied) > 0 {
+			d.Allowed = false
+			d.Reasons = append(d.Reasons, fmt.Sprintf("denied by rule %s for roles %v", rule.describe(), denied))
+		}
+	}
+
+	if len(allowRoles) > 0 {
+		if granted := intersect(effective, allowRoles); len(granted) > 0 {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("route allows roles %v, granted by %v", allowRoles, granted))
+		} else {
+			d.Allowed = false
+			d.Reasons = append(d.Reasons, fmt.Sprintf("route allows roles %v, none of them granted", allowRoles))
+		}
+	}
+
+	for _, rule := range matched {
+		if len(rule.Allow) == 0 {
+			continue
+		}
+		if granted := intersect(effective, rule.Allow); len(granted) > 0 {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("rule %s allows roles %v, granted by %v", rule.describe(), rule.Allow, granted))
+		} else {
+			d.Allowed = false
+			d.Reasons = append(d.Reasons, fmt.Sprintf("rule %s allows roles %v, none of them granted", rule.describe(), rule.Allow))
+		}
+	}
+
+	if len(d.Reasons) == 0 {
+		d.Reasons = append(d.Reasons, "no role requirement")
+	}
+
+	return d
+}
+
+// NeedCheck 判断路由是否需要进行角色校验，没有声明角色且没有匹配规则时可以直接放行
+func (e *Engine) NeedCheck(method string, path string, allowRoles []string) bool {
+	if len(allowRoles) > 0 {
+		return true
+	}
+	for _, rule := range e.policy.Load().policy.Rules {
+		if rule.matches(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterRoute 记录路由声明的 AllowRoles，供 ExplainHandler 按 method、path 查找，同一路由重复注册时覆盖
+func (e *Engine) RegisterRoute(method string, path string, allowRoles []string) {
+	route := &Route{Method: strings.ToUpper(method), Path: path, AllowRoles: allowRoles}
+
+	e.routesMu.Lock()
+	defer e.routesMu.Unlock()
+	for i, r := range e.routes {
+		if r.Method == route.Method && r.Path == route.Path {
+			e.routes[i] = route
+			return
+		}
+	}
+	e.routes = append(e.routes, route)
+}
+
+func (e *Engine) Routes() []*Route {
+	e.routesMu.RLock()
+	defer e.routesMu.RUnlock()
+	return slices.Clone(e.routes)
+}
+
+// MatchRoute 按 method 和实际请求路径查找注册的路由，路由模板完全相同的优先，与 gin 静态路由优先于参数路由一致
+func (e *Engine) MatchRoute(method string, path string) *Route {
+	e.routesMu.RLock()
+	defer e.routesMu.RUnlock()
+
+	var matched *Route
+	for _, r := range e.routes {
+		if !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		if r.Path == path {
+			return r
+		}
+		if matched == nil && MatchPath(r.Path, path) {
+			matched = r
+		}
+	}
+	return matched
+}
+
+// ExplainHandler 调试接口，回答某个用户或角色组合为什么被拒绝。
+// 路由的 AllowRoles 按 method、path 从注册的路由中查找，也可以通过 allowRoles 参数指定
+//
+//	GET /debug/rbac/explain?uid=1&method=POST&path=/order/1/cancel
+//	GET /debug/rbac/explain?roles=editor,intern&method=POST&path=/order/1/cancel
+func (e *Engine) ExplainHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var roles []string
+		if uid := c.Query("uid"); uid != "" {
+			if e.RolesResolver == nil {
+				c.JSON(http.StatusOK, result.SimpleFail[string]("roles resolver not registered"))
+				return
+			}
+			userId, err := strconv.ParseInt(uid, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusOK, result.SimpleFailByError(err))
+				return
+			}
+			if roles, err = e.RolesResolver(userId); err != nil {
+				c.JSON(http.StatusOK, result.SimpleFailByError(err))
+				return
+			}
+		} else {
+			roles = SplitRoles(c.Query("roles"))
+		}
+
+		method := strings.ToUpper(c.DefaultQuery("method", http.MethodGet))
+		path := c.Query("path")
+		var allowRoles []string
+		route := e.MatchRoute(method, path)
+		if ar, ok := c.GetQuery("allowRoles"); ok {
+			allowRoles = SplitRoles(ar)
+		} else if route != nil {
+			allowRoles = route.AllowRoles
+		}
+
+		d := e.Evaluate(roles, method, path, allowRoles)
+		if route != nil {
+			d.Route = route.Path
+		}
+		c.JSON(http.StatusOK, result.Success(d))
+	}
+}
+
+func SplitRoles(roles string) []string {
+	var rs []string
+	for _, r := range strings.Split(roles, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+func compile(policy *Policy) *compiledPolicy {
+	cp := &compiledPolicy{policy: policy, inherits: map[string][]string{}}
+	for _, role := range policy.Roles {
+		cp.inherits[role.Name] = append(cp.inherits[role.Name], role.Inherits...)
+	}
+	return cp
+}
+
+// expand 展开角色继承，admin 继承 editor 时拥有 admin 角色的用户同时拥有 editor 的权限
+func (cp *compiledPolicy) expand(roles []string) map[string]bool {
+	effective := map[string]bool{}
+	var visit func(role string)
+	visit = func(role string) {
+		if effective[role] {
+			return
+		}
+		effective[role] = true
+		for _, parent := range cp.inherits[role] {
+			visit(parent)
+		}
+	}
+	for _, role := range roles {
+		visit(role)
+	}
+	return effective
+}
+
+func intersect(effective map[string]bool, roles []string) []string {
+	var rs []string
+	for _, r := range roles {
+		if effective[r] {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+func sortedRoles(effective map[string]bool) []string {
+	rs := make([]string, 0, len(effective))
+	for r := range effective {
+		rs = append(rs, r)
+	}
+	sort.Strings(rs)
+	return rs
+}
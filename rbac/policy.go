@@ -0,0 +1,174 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Policy 描述角色继承、路由规则和拒绝名单，可以从 YAML/JSON 文件加载或直接在代码中构建
+//
+//	roles:
+//	  - name: admin
+//	    inherits: [editor]
+//	  - name: editor
+//	    inherits: [viewer]
+//	rules:
+//	  - path: /admin/**
+//	    allow: [admin]
+//	  - path: /order/*/cancel
+//	    methods: [POST]
+//	    allow: [editor]
+//	    deny: [intern]
+type Policy struct {
+	Roles []*RoleDef `json:"roles" yaml:"roles"`
+	Rules []*Rule    `json:"rules" yaml:"rules"`
+}
+
+type RoleDef struct {
+	Name     string   `json:"name" yaml:"name"`
+	Inherits []string `json:"inherits" yaml:"inherits"`
+}
+
+type Rule struct {
+	Name    string   `json:"name" yaml:"name"`
+	Path    string   `json:"path" yaml:"path"`
+	Methods []string `json:"methods" yaml:"methods"`
+	Allow   []string `json:"allow" yaml:"allow"`
+	Deny    []string `json:"deny" yaml:"deny"`
+}
+
+func LoadPolicyFile(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(data, policy)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, policy)
+	default:
+		return nil, fmt.Errorf("unsupported policy file: %s", file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, policy.Validate()
+}
+
+func (p *Policy) Validate() error {
+	inherits := map[string][]string{}
+	for _, role := range p.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("role name is empty")
+		}
+		inherits[role.Name] = append(inherits[role.Name], role.Inherits...)
+	}
+
+	// 检查继承关系中的环
+	state := map[string]int{}
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("role inheritance cycle: %s", strings.Join(append(chain, name), " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, parent := range inherits[name] {
+			if err := visit(parent, append(chain, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		return nil
+	}
+	for name := range inherits {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	for i, rule := range p.Rules {
+		if rule.Path == "" {
+			return fmt.Errorf("rule %d has empty path", i)
+		}
+		if len(rule.Allow) == 0 && len(rule.Deny) == 0 {
+			return fmt.Errorf("rule %s has neither allow nor deny roles", rule.describe())
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) describe() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if len(r.Methods) > 0 {
+		return strings.Join(r.Methods, ",") + " " + r.Path
+	}
+	return r.Path
+}
+
+func (r *Rule) matches(method string, path string) bool {
+	if len(r.Methods) > 0 {
+		matched := false
+		for _, m := range r.Methods {
+			if strings.EqualFold(m, method) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return MatchPath(r.Path, path)
+}
+
+// MatchPath 按路径段匹配，* 或 :name 匹配一段，** 匹配任意多段
+func MatchPath(pattern string, path string) bool {
+	return matchSegments(splitPath(pattern), splitPath(path))
+}
+
+func matchSegments(pattern []string, path []string) bool {
+	for len(pattern) > 0 {
+		p := pattern[0]
+		if p == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if p != "*" && !strings.HasPrefix(p, ":") && p != path[0] {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+
+	return len(path) == 0
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
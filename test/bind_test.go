@@ -0,0 +1,89 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderQueryRequest struct {
+	Id         int64             `uri:"id" binding:"required"`
+	AppVersion string            `header:"X-App-Version"`
+	Ids        []int             `form:"ids"`
+	Filter     map[string]string `form:"filter"`
+	Keyword    string            `json:"keyword" form:"keyword" normalize:"trim,lower"`
+	PageSize   int               `json:"pageSize" form:"pageSize" default:"20" binding:"max=100"`
+}
+
+func TestMergedBinding(t *testing.T) {
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[OrderQueryRequest, *result.Result[*OrderQueryRequest]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "orders/:id",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *OrderQueryRequest) *result.Result[*OrderQueryRequest] {
+			return result.Success(req)
+		},
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/public/orders/7?ids=1&ids=2&filter[status]=open&keyword=query",
+		strings.NewReader(`{"keyword":"  Phone  "}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-App-Version", "1.2.0")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+
+	expected := `{"Id":7,"AppVersion":"1.2.0","Ids":[1,2],"Filter":{"status":"open"},"keyword":"phone","pageSize":20}`
+	if !strings.Contains(w.Body.String(), `"data":`+expected) {
+		t.Fatalf("expected data %s, got %s", expected, w.Body.String())
+	}
+}
+
+type DefaultItem struct {
+	Qty int `json:"qty" default:"1"`
+}
+
+type DefaultRequest struct {
+	Enabled  bool           `json:"enabled" form:"enabled" default:"true"`
+	PageSize int            `json:"pageSize" form:"pageSize" default:"20"`
+	Sort     string         `json:"sort" form:"sort" default:"id"`
+	Items    []*DefaultItem `json:"items"`
+}
+
+func TestBindDefaults(t *testing.T) {
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[DefaultRequest, *result.Result[*DefaultRequest]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "defaults",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *DefaultRequest) *result.Result[*DefaultRequest] {
+			return result.Success(req)
+		},
+	})
+
+	cases := []struct {
+		url      string
+		body     string
+		expected string
+	}{
+		{"/public/defaults", `{}`, `{"enabled":true,"pageSize":20,"sort":"id","items":null}`},
+		{"/public/defaults", `{"enabled":false,"pageSize":0,"sort":""}`, `{"enabled":false,"pageSize":0,"sort":"","items":null}`},
+		{"/public/defaults?enabled=false&pageSize=0", `{}`, `{"enabled":false,"pageSize":0,"sort":"id","items":null}`},
+		{"/public/defaults", `{"items":[{},{"qty":3}]}`, `{"enabled":true,"pageSize":20,"sort":"id","items":[{"qty":1},{"qty":3}]}`},
+	}
+	for _, tc := range cases {
+		request := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+		request.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		if !strings.Contains(w.Body.String(), `"data":`+tc.expected) {
+			t.Errorf("%s %s: expected data %s, got %s", tc.url, tc.body, tc.expected, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darwinOrg/go-common/constants"
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := wrapper.NewResponseCache(2)
+
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _ := cache.Load("a", time.Minute, []string{"user"}, func() (any, bool) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return "A", true
+			})
+			if v != "A" {
+				t.Errorf("unexpected value: %v", v)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("loader called %d times", calls)
+	}
+
+	cache.Set("b", "B", time.Minute, []string{"order"})
+	cache.Set("c", "C", time.Minute, []string{"order"})
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("a should be evicted")
+	}
+
+	if n := cache.InvalidateTags("order"); n != 2 || cache.Len() != 0 {
+		t.Fatalf("invalidate %d entries, %d left", n, cache.Len())
+	}
+
+	cache.Set("d", "D", time.Millisecond, nil)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("d"); ok {
+		t.Fatal("d should be expired")
+	}
+}
+
+type CacheRequest struct {
+	Keyword string `form:"keyword"`
+}
+
+func TestCacheRoute(t *testing.T) {
+	var calls int32
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[CacheRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/api"),
+		RelativePath: "profile",
+		Cache:        &wrapper.CacheConfig{TTL: time.Minute, Store: wrapper.NewResponseCache(10)},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *CacheRequest) *result.Result[string] {
+			atomic.AddInt32(&calls, 1)
+			return result.Success(fmt.Sprintf("%d:%s", ctx.UserId, req.Keyword))
+		},
+	})
+
+	serve := func(uid string, keyword string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/profile?keyword="+keyword, nil)
+		request.Header.Set(constants.UID, uid)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	cases := []struct {
+		uid     string
+		keyword string
+		status  string
+		data    string
+	}{
+		{"1", "a", "MISS", `"data":"1:a"`},
+		{"1", "a", "HIT", `"data":"1:a"`},
+		{"2", "a", "MISS", `"data":"2:a"`},
+		{"2", "a", "HIT", `"data":"2:a"`},
+		{"1", "b", "MISS", `"data":"1:b"`},
+	}
+	for i, tc := range cases {
+		w := serve(tc.uid, tc.keyword)
+		if w.Header().Get(wrapper.CacheStatusHeader) != tc.status || !strings.Contains(w.Body.String(), tc.data) {
+			t.Errorf("case %d: expect %s %s, got %s %s", i, tc.status, tc.data, w.Header().Get(wrapper.CacheStatusHeader), w.Body.String())
+		}
+	}
+	if calls != 3 {
+		t.Errorf("biz handler called %d times", calls)
+	}
+}
+
+type CacheTenantRequest struct {
+	Id     int64  `uri:"id"`
+	Tenant string `header:"X-Tenant" json:"-"`
+}
+
+func TestCacheKeyBoundFields(t *testing.T) {
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[CacheTenantRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "tenant/:id",
+		NonLogin:     true,
+		Cache:        &wrapper.CacheConfig{TTL: time.Minute, Store: wrapper.NewResponseCache(10)},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *CacheTenantRequest) *result.Result[string] {
+			return result.Success(fmt.Sprintf("%d:%s", req.Id, req.Tenant))
+		},
+	})
+
+	serve := func(id string, tenant string) string {
+		request := httptest.NewRequest(http.MethodGet, "/public/tenant/"+id, nil)
+		request.Header.Set("X-Tenant", tenant)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w.Body.String()
+	}
+
+	// header 绑定且 json:"-" 的字段同样参与缓存键
+	serve("1", "a")
+	if body := serve("1", "b"); !strings.Contains(body, `"data":"1:b"`) {
+		t.Errorf("header bound field should vary the cache key, got %s", body)
+	}
+	if body := serve("2", "a"); !strings.Contains(body, `"data":"2:a"`) {
+		t.Errorf("uri bound field should vary the cache key, got %s", body)
+	}
+}
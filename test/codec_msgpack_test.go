@@ -0,0 +1,23 @@
+//go:build !nomsgpack
+
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ugorji/go/codec"
+)
+
+func TestNegotiateMsgPack(t *testing.T) {
+	w := serveAccept(newCodecEngine(), "/public/multi", "application/x-msgpack")
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	var rt map[string]any
+	if err := codec.NewDecoderBytes(w.Body.Bytes(), handle).Decode(&rt); err != nil {
+		t.Fatalf("decode msgpack error: %v", err)
+	}
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/msgpack; charset=utf-8" || rt["data"] != "ok" {
+		t.Errorf("unexpected msgpack response %d %s %v", w.Code, w.Header().Get("Content-Type"), rt)
+	}
+}
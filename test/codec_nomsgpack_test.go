@@ -0,0 +1,21 @@
+//go:build nomsgpack
+
+package test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+func TestNegotiateMsgPack(t *testing.T) {
+	if wrapper.GetCodec(wrapper.FORMAT_MSGPACK) != nil {
+		t.Fatal("msgpack codec should not be registered with nomsgpack")
+	}
+	// 没有 msgpack 编码时回退为 JSON
+	if w := serveAccept(newCodecEngine(), "/public/multi", "application/x-msgpack"); w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("expect json without msgpack codec, got %d %s", w.Code, w.Body.String())
+	}
+}
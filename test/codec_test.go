@@ -0,0 +1,101 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newCodecEngine() *gin.Engine {
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "greeting",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+			return result.Success("ok")
+		},
+	})
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "multi",
+		NonLogin:     true,
+		Formats:      []string{wrapper.FORMAT_JSON, wrapper.FORMAT_XML, wrapper.FORMAT_YAML, wrapper.FORMAT_MSGPACK},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+			return result.Success("ok")
+		},
+	})
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *wrapperspb.StringValue]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "proto",
+		NonLogin:     true,
+		Formats:      []string{wrapper.FORMAT_JSON, wrapper.FORMAT_PROTOBUF},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *wrapperspb.StringValue {
+			return wrapperspb.String("ok")
+		},
+	})
+	return engine
+}
+
+func serveAccept(engine *gin.Engine, url string, accept string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+	return w
+}
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+func TestNegotiateCodec(t *testing.T) {
+	engine := newCodecEngine()
+
+	cases := []struct {
+		url         string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		// 没有声明 Formats 时只输出 JSON，浏览器、text/html 等请求同样返回 JSON
+		{"/public/greeting", "", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/greeting", "*/*", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/greeting", "application/xml", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/greeting", "application/x-protobuf", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/greeting", "text/html", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/greeting", browserAccept, http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "*/*", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "application/*", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "text/html", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "application/xml", http.StatusOK, "application/xml", "<result>"},
+		{"/public/multi", "application/x-yaml", http.StatusOK, "application/yaml", "data: ok"},
+		{"/public/multi", "application/json;q=0.5, application/x-yaml", http.StatusOK, "application/yaml", "data: ok"},
+		{"/public/multi", "application/xml;q=0.2, application/json;q=0.8", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "application/xml;q=0, application/json", http.StatusOK, "application/json", `"data":"ok"`},
+		{"/public/multi", "text/csv, */*;q=0.1", http.StatusOK, "application/json", `"data":"ok"`},
+	}
+	for _, tc := range cases {
+		w := serveAccept(engine, tc.url, tc.accept)
+		if w.Code != tc.status || !strings.HasPrefix(w.Header().Get("Content-Type"), tc.contentType) || !strings.Contains(w.Body.String(), tc.body) {
+			t.Errorf("%s Accept %q: expect %d %s %s, got %d %s %s", tc.url, tc.accept, tc.status, tc.contentType, tc.body,
+				w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	w := serveAccept(engine, "/public/proto", "application/x-protobuf")
+	msg := &wrapperspb.StringValue{}
+	if err := proto.Unmarshal(w.Body.Bytes(), msg); err != nil || msg.GetValue() != "ok" || w.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Errorf("unexpected protobuf response %s %v %v", w.Header().Get("Content-Type"), msg, err)
+	}
+}
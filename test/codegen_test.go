@@ -0,0 +1,209 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	dghttp "github.com/darwinOrg/go-httpclient"
+	"github.com/darwinOrg/go-web/client"
+	"github.com/darwinOrg/go-web/codegen"
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+var updateGolden = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+type CodegenItem struct {
+	Id     int64    `json:"id" remark:"编号"`
+	Name   string   `json:"name" binding:"required"`
+	Status string   `json:"status" binding:"oneof=open closed"`
+	Level  int64    `json:"level" binding:"oneof=1 9007199254740993"`
+	Tags   []string `json:"tags,omitempty"`
+	Owner  *UserResponse
+}
+
+type CodegenPage[T any] struct {
+	Total int64 `json:"total"`
+	List  []T   `json:"list"`
+}
+
+type CodegenQuery struct {
+	Keyword   string `form:"keyword"`
+	CompanyId int64  `form:"companyId" binding:"required"`
+	Tenant    string `header:"X-Tenant"`
+}
+
+func codegenApis() []*wrapper.RequestApi {
+	return []*wrapper.RequestApi{
+		{
+			Method:         http.MethodGet,
+			BasePath:       "/public",
+			RelativePath:   "items",
+			Remark:         "查询列表",
+			RequestObject:  new(CodegenQuery),
+			ResponseObject: new(*result.Result[*CodegenPage[*CodegenItem]]),
+		},
+		{
+			Method:         http.MethodPost,
+			BasePath:       "/public",
+			RelativePath:   "item/:id",
+			Remark:         "保存",
+			RequestObject:  new(CodegenItem),
+			ResponseObject: new(*result.Result[int64]),
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file error: %v, run go test -update to create it", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s mismatch, run go test -update and review the diff\n%s", name, actual)
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	for _, mode := range []codegen.Int64Mode{codegen.INT64_MODE_STRING, codegen.INT64_MODE_BIGINT} {
+		code, err := codegen.GenerateTypeScriptFromRequestApis(codegenApis(), &codegen.TypeScriptOptions{Int64Mode: mode, Headers: []string{"uid"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, "client_"+string(mode)+".ts.golden", code)
+	}
+
+	// tsgen 从 JSON 文档生成时，int64 枚举值不能经过 float64
+	data, _ := json.Marshal(wrapper.BuildOpenApi(nil, codegenApis()))
+	doc, err := codegen.ParseOpenApiDoc(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := codegen.GenerateTypeScript(doc, &codegen.TypeScriptOptions{Headers: []string{"uid"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "client_string.ts.golden", code)
+}
+
+// buildGoPackage 编译 testdata 下生成的代码，testdata 不参与 go build ./...
+func buildGoPackage(t *testing.T, dir string, code []byte) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), code, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("go", "build", "./"+filepath.ToSlash(dir)).CombinedOutput(); err != nil {
+		t.Fatalf("generated client does not compile: %v\n%s\n%s", err, out, code)
+	}
+}
+
+func TestGenerateGoClient(t *testing.T) {
+	apis := []*wrapper.RequestApi{
+		{
+			Method:         http.MethodGet,
+			BasePath:       "/public",
+			RelativePath:   "exports/:format",
+			Remark:         "导出记录",
+			RequestObject:  new(wrapper.EmptyRequest),
+			ResponseObject: new(*result.Result[[]*wrapper.ExportEvent]),
+		},
+		{
+			Method:         http.MethodPost,
+			BasePath:       "/public",
+			RelativePath:   "schemas",
+			RequestObject:  new(wrapper.OpenApiInfo),
+			ResponseObject: new(*result.Result[map[string]*wrapper.OpenApiSchema]),
+		},
+	}
+	code, err := codegen.GenerateGoClient(apis, &codegen.GoClientOptions{PackageName: "goclient"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "goclient/client.go", code)
+	buildGoPackage(t, filepath.Join("testdata", "goclient"), code)
+
+	// 没有接口时也要能编译
+	code, err = codegen.GenerateGoClient(nil, &codegen.GoClientOptions{PackageName: "goclient"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("testdata", "goclient_empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	buildGoPackage(t, dir, code)
+}
+
+type ClientQuery struct {
+	Enabled bool   `form:"enabled"`
+	Page    int    `form:"page"`
+	Size    int    `form:"size,omitempty"`
+	Keyword string `form:"keyword,omitempty"`
+}
+
+func TestClientExplicitZeroQuery(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	_, err := client.Get[ClientQuery, *result.Result[*result.Void]](&dgctx.DgContext{}, &dghttp.DgHttpClient{}, server.URL, &ClientQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "enabled=false&page=0" {
+		t.Errorf("explicit zero values should be sent unless omitempty, got %s", query)
+	}
+}
+
+type ClientOrderQuery struct {
+	Id      int64  `uri:"id"`
+	Tenant  string `header:"X-Tenant"`
+	Keyword string `form:"keyword"`
+}
+
+func TestClientUriAndHeaderFields(t *testing.T) {
+	var query, tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, tenant = r.URL.RawQuery, r.Header.Get("X-Tenant")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	_, err := client.Get[ClientOrderQuery, *result.Result[*result.Void]](&dgctx.DgContext{}, &dghttp.DgHttpClient{}, server.URL+"/order/1", &ClientOrderQuery{Id: 1, Tenant: "t1", Keyword: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "keyword=k" || tenant != "t1" {
+		t.Errorf("uri fields should be skipped and header fields sent as headers, got query %q, header %q", query, tenant)
+	}
+
+	_, err = client.Post[ClientOrderQuery, *result.Result[*result.Void]](&dgctx.DgContext{}, &dghttp.DgHttpClient{}, server.URL+"/order/1", &ClientOrderQuery{Tenant: "t2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenant != "t2" {
+		t.Errorf("post should send header fields as headers, got %q", tenant)
+	}
+}
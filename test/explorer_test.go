@@ -0,0 +1,48 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+func TestApiExplorer(t *testing.T) {
+	defer func(isProd func() bool) {
+		wrapper.ApiExplorer = nil
+		wrapper.ApiExplorerIsProd = isProd
+	}(wrapper.ApiExplorerIsProd)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		wrapper.NewEngine().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	wrapper.ApiExplorer = &wrapper.ApiExplorerConfig{Path: "docs"}
+	w := serve("/docs")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<title>API Explorer</title>") {
+		t.Fatalf("explorer should be mounted, got %d", w.Code)
+	}
+	if w = serve("/docs/openapi.json"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"openapi"`) {
+		t.Fatalf("openapi spec should be mounted, got %d", w.Code)
+	}
+
+	wrapper.ApiExplorerIsProd = func() bool { return true }
+	if w = serve("/docs"); w.Code != http.StatusNotFound {
+		t.Fatalf("explorer should not be mounted in prod, got %d", w.Code)
+	}
+
+	wrapper.ApiExplorer.EnableInProd = true
+	if w = serve("/docs"); w.Code != http.StatusOK {
+		t.Fatalf("explorer should be mounted when EnableInProd, got %d", w.Code)
+	}
+
+	wrapper.ApiExplorer = nil
+	wrapper.ApiExplorerIsProd = func() bool { return false }
+	if w = serve("/api-explorer"); w.Code != http.StatusNotFound {
+		t.Fatalf("explorer should not be mounted without config, got %d", w.Code)
+	}
+}
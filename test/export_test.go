@@ -0,0 +1,76 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderRow struct {
+	Id     int64   `json:"id" title:"编号" title_en:"ID"`
+	Name   string  `json:"name" title:"名称" title_en:"Name"`
+	Amount float64 `json:"amount" remark:"金额"`
+	Secret string  `json:"secret" export:"-"`
+}
+
+func TestExport(t *testing.T) {
+	var events []*wrapper.ExportEvent
+	wrapper.RegisterExportProcessor(func(ctx *dgctx.DgContext, event *wrapper.ExportEvent) {
+		events = append(events, event)
+	})
+
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[[]*OrderRow]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "orders",
+		Remark:       "订单",
+		NonLogin:     true,
+		Export:       true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[[]*OrderRow] {
+			return result.Success([]*OrderRow{
+				{Id: 1, Name: "a,b", Amount: 1.5, Secret: "x"},
+				{Id: 2, Name: "=SUM(A1)", Amount: 2},
+			})
+		},
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/public/orders?export=csv", nil)
+	request.Header.Set("Accept-Language", "en-US")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+	expected := "\xEF\xBB\xBFID,Name,金额\n1,\"a,b\",1.5\n2,'=SUM(A1),2\n"
+	if w.Body.String() != expected {
+		t.Errorf("unexpected csv %q", w.Body.String())
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/public/orders", nil)
+	request.Header.Set("Accept", wrapper.MIMEXLSX)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("invalid xlsx: %v", err)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("sheet not found: %v", err)
+	}
+	sheet, _ := io.ReadAll(f)
+	_ = f.Close()
+	if !strings.Contains(string(sheet), `<t xml:space="preserve">编号</t>`) || !strings.Contains(string(sheet), "<c><v>1.5</v></c>") {
+		t.Errorf("unexpected sheet %s", sheet)
+	}
+
+	if len(events) != 2 || events[0].Format != wrapper.EXPORT_CSV || events[1].Rows != 2 {
+		t.Errorf("unexpected export events %d", len(events))
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type AddressRequest struct {
+	City string `json:"city" binding:"required"`
+}
+
+type ContactRequest struct {
+	Name    string          `json:"name" binding:"required"`
+	Age     int             `json:"age" binding:"min=18"`
+	Address *AddressRequest `json:"address" binding:"required"`
+}
+
+func TestTranslateFieldErrors(t *testing.T) {
+	err := binding.Validator.ValidateStruct(&ContactRequest{Age: 3, Address: &AddressRequest{}})
+	fieldErrors := utils.TranslateFieldErrors(err, "en")
+
+	expected := []*utils.FieldError{
+		{Field: "name", Tag: "required"},
+		{Field: "age", Tag: "min", Param: "18"},
+		{Field: "address.city", Tag: "required"},
+	}
+	if len(fieldErrors) != len(expected) {
+		t.Fatalf("expect %d field errors, got %d: %v", len(expected), len(fieldErrors), err)
+	}
+	for i, fe := range fieldErrors {
+		if fe.Field != expected[i].Field || fe.Tag != expected[i].Tag || fe.Param != expected[i].Param || fe.Message == "" {
+			t.Errorf("field error %d: expect %+v, got %+v", i, expected[i], fe)
+		}
+	}
+
+	custom := utils.FieldErrors{{Field: "name", Tag: "exists", Message: "name exists"}}
+	if fes := utils.TranslateFieldErrors(custom, "en"); len(fes) != 1 || fes[0] != custom[0] {
+		t.Errorf("FieldErrors should be returned as is, got %v", fes)
+	}
+	if fes := utils.TranslateFieldErrors(errors.New("eof"), "en"); fes != nil {
+		t.Errorf("non validation error should return nil, got %v", fes)
+	}
+}
+
+func TestValidateFailResult(t *testing.T) {
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[ContactRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "contact",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *ContactRequest) *result.Result[string] {
+			return result.Success(req.Name)
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/public/contact", strings.NewReader(`{"age":20,"address":{}}`))
+	r.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, r)
+
+	var rt struct {
+		Success bool                `json:"success"`
+		Message string              `json:"message"`
+		Errors  []*utils.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &rt); err != nil {
+		t.Fatal(err)
+	}
+	if rt.Success || len(rt.Errors) != 2 || rt.Errors[0].Field != "name" || rt.Errors[1].Field != "address.city" {
+		t.Fatalf("unexpected validate fail result %s", w.Body.String())
+	}
+	if rt.Message != utils.JoinFieldErrorMessages(rt.Errors) {
+		t.Errorf("message %q should join field error messages", rt.Message)
+	}
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestFileResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	_ = os.WriteFile(path, []byte("0123456789"), 0o644)
+
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *wrapper.FileResponse]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "download",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *wrapper.FileResponse {
+			return wrapper.FileFromPath(path, "报表.csv")
+		},
+	})
+
+	serve := func(headers ...string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/public/download", nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	w := serve()
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	expected := `attachment; filename="__.csv"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.csv`
+	if actual := w.Header().Get("Content-Disposition"); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	etag := w.Header().Get("ETag")
+
+	w = serve("Range", "bytes=2-4", "If-Range", etag)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Errorf("unexpected range response %d %s", w.Code, w.Body.String())
+	}
+
+	w = serve("Range", "bytes=2-4", "If-Range", `W/"stale"`)
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Errorf("stale If-Range should return full content, got %d", w.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darwinOrg/go-common/constants"
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateOrderRequest struct {
+	Name string `json:"name"`
+}
+
+func TestIdempotentHandler(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[CreateOrderRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "orders",
+		NonLogin:     true,
+		Idempotent:   true,
+		Formats:      []string{wrapper.FORMAT_JSON, wrapper.FORMAT_YAML},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *CreateOrderRequest) *result.Result[string] {
+			atomic.AddInt32(&calls, 1)
+			if req.Name == "slow" {
+				<-release
+			}
+			return result.Success(req.Name)
+		},
+	})
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[int32]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "orders",
+		NonLogin:     true,
+		Idempotent:   true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[int32] {
+			return result.Success(atomic.AddInt32(&calls, 1))
+		},
+	})
+
+	serve := func(method string, key string, body string, accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/public/orders", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(wrapper.IdempotencyKeyHeader, key)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	// 未使用 CopyBody 时同样按请求体计算指纹，重放首次响应的原始内容和 Content-Type
+	first := serve(http.MethodPost, "k1", `{"name":"a"}`, "application/x-yaml")
+	replay := serve(http.MethodPost, "k1", `{"name":"a"}`, "")
+	if calls != 1 || replay.Header().Get(wrapper.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expect replay, calls %d, headers %v", calls, replay.Header())
+	}
+	if !strings.Contains(first.Header().Get("Content-Type"), "yaml") {
+		t.Fatalf("unexpected first response %q", first.Header().Get("Content-Type"))
+	}
+	if replay.Code != first.Code || replay.Body.String() != first.Body.String() || replay.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replay %d %q %s differs from first %d %q %s", replay.Code, replay.Header().Get("Content-Type"), replay.Body.String(),
+			first.Code, first.Header().Get("Content-Type"), first.Body.String())
+	}
+
+	w := serve(http.MethodPost, "k1", `{"name":"b"}`, "")
+	if calls != 1 || !strings.Contains(w.Body.String(), "different request body") {
+		t.Errorf("expect key reused conflict, got %s", w.Body.String())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(http.MethodPost, "k2", `{"name":"slow"}`, "")
+	}()
+	for atomic.LoadInt32(&calls) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	w = serve(http.MethodPost, "k2", `{"name":"slow"}`, "")
+	close(release)
+	<-done
+	if calls != 2 || !strings.Contains(w.Body.String(), "being processed") {
+		t.Errorf("expect in flight conflict, got %s", w.Body.String())
+	}
+	if w = serve(http.MethodPost, "k2", `{"name":"slow"}`, ""); calls != 2 || w.Header().Get(wrapper.IdempotentReplayedHeader) != "true" {
+		t.Errorf("expect replay after completion, calls %d", calls)
+	}
+
+	serve(http.MethodGet, "k3", "", "")
+	if w = serve(http.MethodGet, "k3", "", ""); calls != 4 || w.Header().Get(wrapper.IdempotentReplayedHeader) != "" {
+		t.Errorf("GET should not be replayed, calls %d", calls)
+	}
+
+	// 未登录时不同客户端 IP、不同 token 的调用方不共用幂等键
+	serveAs := func(remoteAddr string, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/public/orders", strings.NewReader(`{"name":"a"}`))
+		request.RemoteAddr = remoteAddr
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(wrapper.IdempotencyKeyHeader, "k1")
+		if token != "" {
+			request.Header.Set(constants.Token, token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+	if w = serveAs("10.0.0.2:1234", ""); calls != 5 || w.Header().Get(wrapper.IdempotentReplayedHeader) != "" {
+		t.Errorf("other client ip should not share the key, calls %d", calls)
+	}
+	serveAs("10.0.0.2:1234", "t1")
+	if w = serveAs("10.0.0.3:1234", "t1"); calls != 6 || w.Header().Get(wrapper.IdempotentReplayedHeader) != "true" {
+		t.Errorf("same token should share the key, calls %d", calls)
+	}
+}
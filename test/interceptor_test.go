@@ -0,0 +1,81 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestInterceptors(t *testing.T) {
+	var trace []string
+	engine := gin.New()
+	engine.Use(wrapper.UseInterceptors(&wrapper.Interceptor{
+		Name:  "engine",
+		Order: 10,
+		Around: func(inv *wrapper.Invocation, next wrapper.Invoker) any {
+			trace = append(trace, "engine:before")
+			rt := next(inv)
+			trace = append(trace, "engine:after")
+			return rt
+		},
+	}))
+
+	wrapper.Get(&wrapper.RequestHolder[UserRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "intercept",
+		NonLogin:     true,
+		Interceptors: []*wrapper.TypedInterceptor[UserRequest, *result.Result[string]]{
+			{
+				Name:  "auth",
+				Order: 1,
+				Before: func(c *gin.Context, ctx *dgctx.DgContext, req *UserRequest) (*result.Result[string], bool) {
+					trace = append(trace, "auth")
+					if req.Name == "" {
+						return result.SimpleFail[string]("name required"), true
+					}
+					req.Name = strings.ToUpper(req.Name)
+					return nil, false
+				},
+			},
+			{
+				Name:  "suffix",
+				Order: 20,
+				After: func(c *gin.Context, ctx *dgctx.DgContext, req *UserRequest, rt *result.Result[string]) *result.Result[string] {
+					trace = append(trace, "suffix")
+					rt.Data += "!"
+					return rt
+				},
+			},
+		},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *UserRequest) *result.Result[string] {
+			trace = append(trace, "biz")
+			return result.Success(req.Name)
+		},
+	})
+
+	cases := []struct {
+		query string
+		body  string
+		trace string
+	}{
+		{"?name=tom", `"data":"TOM!"`, "auth,engine:before,biz,suffix,engine:after"},
+		{"", `"message":"name required"`, "auth"},
+	}
+	for _, c := range cases {
+		trace = nil
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public/intercept"+c.query, nil))
+		if !strings.Contains(w.Body.String(), c.body) {
+			t.Errorf("%s: unexpected body %s", c.query, w.Body.String())
+		}
+		if actual := strings.Join(trace, ","); actual != c.trace {
+			t.Errorf("%s: expected trace %s, got %s", c.query, c.trace, actual)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// captureStructuredLog 把结构化日志以 zap 的 JSON 编码写到返回的 buffer 中
+func captureStructuredLog(t *testing.T) *bytes.Buffer {
+	origin := middleware.DefaultStructuredLogger
+	t.Cleanup(func() {
+		middleware.DefaultStructuredLogger = origin
+		middleware.DefaultLogFormat = middleware.LOG_FORMAT_TEXT
+	})
+
+	var buf bytes.Buffer
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zap.InfoLevel))
+	middleware.DefaultStructuredLogger = func(ctx *dgctx.DgContext, msg string, fields ...zap.Field) {
+		logger.Info(msg, fields...)
+	}
+	middleware.DefaultLogFormat = middleware.LOG_FORMAT_FIELDS
+	return &buf
+}
+
+func TestStructuredLog(t *testing.T) {
+	buf := captureStructuredLog(t)
+
+	engine := gin.New()
+	engine.Use(middleware.LoggerWithFormat(middleware.LOG_FORMAT_FIELDS))
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[*result.Void]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "missing",
+		NonLogin:     true,
+		RestStatus:   true,
+		LogLevel:     wrapper.LOG_LEVEL_ALL,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[*result.Void] {
+			return result.SimpleFailByError(&dgerr.DgError{Code: http.StatusNotFound, Message: "not found"})
+		},
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/public/missing", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expect biz and request logs, got %q", buf.String())
+	}
+	var biz, request map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &biz); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &request); err != nil {
+		t.Fatal(err)
+	}
+	if biz["msg"] != "biz" || biz["status"] != float64(http.StatusNotFound) || biz["route"] != "/public/missing" || biz["success"] != false {
+		t.Errorf("unexpected biz log %s", lines[0])
+	}
+	if rt, ok := biz["result"].(map[string]any); !ok || rt["message"] != "not found" {
+		t.Errorf("result should be a structured field, got %s", lines[0])
+	}
+	if request["msg"] != "request" || request["status"] != float64(http.StatusNotFound) || request["method"] != http.MethodGet {
+		t.Errorf("unexpected request log %s", lines[1])
+	}
+
+	buf.Reset()
+	middleware.LogStructured(&dgctx.DgContext{}, "biz", middleware.LogFields{}.
+		Add("cost", 1500*time.Millisecond).
+		Add("error", errors.New("bad request")).
+		Add("params", json.RawMessage(`{"id":1}`)).
+		Add("invalid", json.RawMessage(`{"id":`)))
+	if !strings.Contains(buf.String(), `"cost":"1.5s","error":"bad request","params":{"id":1},"invalid":"{\"id\":"`) {
+		t.Errorf("unexpected fields %s", buf.String())
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+)
+
+type openApiRequest struct {
+	Name   string   `json:"name" form:"name" binding:"required,max=20" remark:"名称"`
+	Age    int      `json:"age" form:"age" binding:"min=1,max=150" remark:"年龄"`
+	Status string   `json:"status" form:"status" binding:"oneof=open closed"`
+	Tags   []string `json:"tags" form:"tags" binding:"max=5,dive,max=10"`
+	Secret string   `json:"-" form:"-"`
+}
+
+type openApiPathRequest struct {
+	Id      int64  `uri:"id" remark:"订单编号"`
+	Token   string `header:"X-Token" binding:"required"`
+	Keyword string `form:"keyword"`
+}
+
+func TestBuildOpenApi(t *testing.T) {
+	apis := []*wrapper.RequestApi{
+		{
+			Method:         http.MethodGet,
+			BasePath:       "/public",
+			RelativePath:   "user/:id",
+			Remark:         "查询用户",
+			RequestObject:  new(openApiRequest),
+			ResponseObject: new(*result.Result[*UserResponse]),
+		},
+		{
+			Method:         http.MethodPost,
+			BasePath:       "/public",
+			RelativePath:   "user",
+			Remark:         "创建用户",
+			RequestObject:  new(openApiRequest),
+			ResponseObject: new(*result.Result[*UserResponse]),
+		},
+		{
+			Method:         http.MethodGet,
+			BasePath:       "/public",
+			RelativePath:   "order/:id",
+			RequestObject:  new(openApiPathRequest),
+			ResponseObject: new(*result.Result[*UserResponse]),
+		},
+		{
+			Method:         http.MethodPost,
+			BasePath:       "/public",
+			RelativePath:   "order/:id",
+			RequestObject:  new(openApiPathRequest),
+			ResponseObject: new(*result.Result[*UserResponse]),
+		},
+	}
+
+	doc := wrapper.BuildOpenApi(nil, apis)
+
+	get := doc.Paths["/public/user/{id}"].Get
+	if get == nil || get.Summary != "查询用户" {
+		t.Fatalf("unexpected get operation: %+v", get)
+	}
+	if len(get.Parameters) != 5 || get.Parameters[0].In != "path" || get.Parameters[1].Name != "name" || !get.Parameters[1].Required {
+		t.Fatalf("unexpected get parameters: %+v", get.Parameters)
+	}
+
+	order := doc.Paths["/public/order/{id}"].Get
+	if len(order.Parameters) != 3 {
+		t.Fatalf("uri field should not be duplicated as query parameter: %+v", order.Parameters)
+	}
+	if id := order.Parameters[0]; id.In != "path" || id.Schema.Format != "int64" || id.Description != "订单编号" {
+		t.Fatalf("unexpected path parameter: %+v", id)
+	}
+	if token := order.Parameters[1]; token.In != "header" || token.Name != "X-Token" || !token.Required {
+		t.Fatalf("unexpected header parameter: %+v", token)
+	}
+	if keyword := order.Parameters[2]; keyword.In != "query" || keyword.Name != "keyword" {
+		t.Fatalf("unexpected query parameter: %+v", keyword)
+	}
+
+	// uri、header 字段只作为参数，不出现在请求体中
+	orderPost := doc.Paths["/public/order/{id}"].Post
+	if len(orderPost.Parameters) != 2 || orderPost.RequestBody == nil {
+		t.Fatalf("unexpected order post operation: %+v", orderPost)
+	}
+	body := orderPost.RequestBody.Content["application/json"].Schema
+	if _, ok := body.Properties["Keyword"]; len(body.Properties) != 1 || !ok || len(body.Required) != 0 {
+		t.Fatalf("body should only contain non-bound fields: %+v", body)
+	}
+
+	post := doc.Paths["/public/user"].Post
+	if post == nil || post.RequestBody == nil {
+		t.Fatalf("unexpected post operation: %+v", post)
+	}
+
+	schema := doc.Components.Schemas["openApiRequest"]
+	if schema == nil || len(schema.Required) != 1 || schema.Required[0] != "name" {
+		t.Fatalf("unexpected request schema: %+v", schema)
+	}
+	if age := schema.Properties["age"]; *age.Minimum != 1 || *age.Maximum != 150 || age.Description != "年龄" {
+		t.Fatalf("unexpected age schema: %+v", age)
+	}
+	if status := schema.Properties["status"]; len(status.Enum) != 2 {
+		t.Fatalf("unexpected status schema: %+v", status)
+	}
+	if tags := schema.Properties["tags"]; *tags.MaxItems != 5 || *tags.Items.MaxLength != 10 {
+		t.Fatalf("unexpected tags schema: %+v", tags)
+	}
+	if _, ok := schema.Properties["Secret"]; ok {
+		t.Fatal("ignored field should not be exported")
+	}
+	if doc.Components.Schemas["Result_UserResponse"] == nil {
+		t.Fatalf("missing result schema: %v", doc.Components.Schemas)
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHasPermissions(t *testing.T) {
+	cases := []struct {
+		granted []string
+		needed  []string
+		mode    wrapper.PermissionMatchMode
+		allowed bool
+	}{
+		{[]string{"order:read"}, []string{"order:read"}, wrapper.PERMISSION_MATCH_ANY, true},
+		{[]string{"order:*"}, []string{"order:read"}, wrapper.PERMISSION_MATCH_ANY, true},
+		{[]string{"order:*"}, []string{"order:item:delete"}, wrapper.PERMISSION_MATCH_ANY, true},
+		{[]string{"order:*"}, []string{"order"}, wrapper.PERMISSION_MATCH_ANY, false},
+		{[]string{"order:*"}, []string{"user:read"}, wrapper.PERMISSION_MATCH_ANY, false},
+		{[]string{"*:read"}, []string{"user:read"}, wrapper.PERMISSION_MATCH_ANY, true},
+		{[]string{"*:read"}, []string{"user:write"}, wrapper.PERMISSION_MATCH_ANY, false},
+		{[]string{"*"}, []string{"user:write"}, wrapper.PERMISSION_MATCH_ANY, true},
+		{[]string{"order:read"}, []string{"order:read", "order:write"}, wrapper.PERMISSION_MATCH_ANY, true},
+		{[]string{"order:read"}, []string{"order:read", "order:write"}, wrapper.PERMISSION_MATCH_ALL, false},
+		{[]string{"order:*"}, []string{"order:read", "order:write"}, wrapper.PERMISSION_MATCH_ALL, true},
+		{nil, nil, wrapper.PERMISSION_MATCH_ALL, true},
+		{nil, []string{"order:read"}, wrapper.PERMISSION_MATCH_ANY, false},
+	}
+	for _, c := range cases {
+		if allowed := wrapper.HasPermissions(c.granted, c.needed, c.mode); allowed != c.allowed {
+			t.Errorf("%v %v mode %d: expect %v, got %v", c.granted, c.needed, c.mode, c.allowed, allowed)
+		}
+	}
+}
+
+func TestCheckPermissionsHandler(t *testing.T) {
+	defer func() {
+		wrapper.EnablePermissionsCheck = true
+		wrapper.RegisterPermissionChecker(nil)
+	}()
+
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+		RouterGroup:     engine.Group("/public"),
+		RelativePath:    "orders",
+		NonLogin:        true,
+		NeedPermissions: []string{"order:read"},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+			return result.Success("ok")
+		},
+	})
+	serve := func() string {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public/orders", nil))
+		return w.Body.String()
+	}
+
+	// 默认开启，未注册权限查询时一律拒绝
+	if body := serve(); !strings.Contains(body, dgerr.NO_PERMISSION.Message) {
+		t.Fatalf("check without checker should be rejected by default, got %s", body)
+	}
+
+	wrapper.EnablePermissionsCheck = false
+	if body := serve(); !strings.Contains(body, `"data":"ok"`) {
+		t.Fatalf("disabled check should be allowed, got %s", body)
+	}
+
+	wrapper.EnablePermissionsCheck = true
+
+	wrapper.RegisterPermissionChecker(wrapper.PermissionCheckerFunc(func(ctx *dgctx.DgContext) ([]string, error) {
+		return nil, errors.New("dial tcp 10.0.0.1:3306: connection refused")
+	}))
+	if body := serve(); strings.Contains(body, "10.0.0.1") || !strings.Contains(body, dgerr.NO_PERMISSION.Message) {
+		t.Fatalf("checker error should not be exposed, got %s", body)
+	}
+
+	wrapper.RegisterPermissionChecker(wrapper.PermissionCheckerFunc(func(ctx *dgctx.DgContext) ([]string, error) {
+		return []string{"order:*"}, nil
+	}))
+	if body := serve(); !strings.Contains(body, `"data":"ok"`) {
+		t.Fatalf("wildcard permission should be allowed, got %s", body)
+	}
+}
+
+func TestCachedPermissionChecker(t *testing.T) {
+	var calls int
+	checker := wrapper.NewCachedPermissionChecker(wrapper.PermissionCheckerFunc(func(ctx *dgctx.DgContext) ([]string, error) {
+		calls++
+		if ctx.UserId < 0 {
+			return nil, errors.New("query error")
+		}
+		return []string{"order:read"}, nil
+	}), time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if permissions, err := checker.GetPermissions(&dgctx.DgContext{UserId: 1}); err != nil || len(permissions) != 1 {
+			t.Fatalf("unexpected permissions %v, error %v", permissions, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expect cached permissions, got %d calls", calls)
+	}
+
+	// 查询失败不缓存
+	for i := 0; i < 2; i++ {
+		if _, err := checker.GetPermissions(&dgctx.DgContext{UserId: -1}); err == nil {
+			t.Fatal("expect error")
+		}
+	}
+	if calls != 3 {
+		t.Errorf("errors should not be cached, got %d calls", calls)
+	}
+
+	// 超出容量时淘汰最久未使用的
+	for i := int64(2); i <= 10001; i++ {
+		_, _ = checker.GetPermissions(&dgctx.DgContext{UserId: i})
+	}
+	calls = 0
+	_, _ = checker.GetPermissions(&dgctx.DgContext{UserId: 1})
+	_, _ = checker.GetPermissions(&dgctx.DgContext{UserId: 10001})
+	if calls != 1 {
+		t.Errorf("expect least recently used entry evicted, got %d calls", calls)
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darwinOrg/go-common/constants"
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type ProblemRequest struct {
+	Name string `form:"name" binding:"required"`
+}
+
+func TestProblemErrorRenderer(t *testing.T) {
+	engine := gin.New()
+	engine.Use(middleware.Recover())
+	holder := func(path string, nonLogin bool, allowRoles []string) *wrapper.RequestHolder[ProblemRequest, *result.Result[string]] {
+		return &wrapper.RequestHolder[ProblemRequest, *result.Result[string]]{
+			RouterGroup:   engine.Group("/api"),
+			RelativePath:  path,
+			NonLogin:      nonLogin,
+			AllowRoles:    allowRoles,
+			ErrorRenderer: &middleware.ProblemErrorRenderer{BaseUri: "https://example.com/problems/"},
+			BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *ProblemRequest) *result.Result[string] {
+				if req.Name == "panic" {
+					panic(errors.New("boom"))
+				}
+				return result.Success(req.Name)
+			},
+		}
+	}
+	wrapper.Get(holder("login", false, nil))
+	wrapper.Get(holder("role", false, []string{"admin"}))
+	wrapper.Get(holder("bind", true, nil))
+
+	cases := []struct {
+		name   string
+		url    string
+		uid    string
+		typ    string
+		status int
+		errors int
+	}{
+		{"login", "/api/login?name=a", "", "https://example.com/problems/not-login", http.StatusUnauthorized, 0},
+		{"role", "/api/role?name=a", "1", "https://example.com/problems/no-permission", http.StatusForbidden, 0},
+		{"bind", "/api/bind", "", "https://example.com/problems/validation-error", http.StatusBadRequest, 1},
+		{"recover", "/api/bind?name=panic", "", "about:blank", http.StatusInternalServerError, 0},
+	}
+	for _, tc := range cases {
+		request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		request.Header.Set(constants.TraceId, "trace-"+tc.name)
+		request.Header.Set(constants.Roles, "viewer")
+		if tc.uid != "" {
+			request.Header.Set(constants.UID, tc.uid)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+
+		var problem middleware.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("%s: invalid problem %s: %v", tc.name, w.Body.String(), err)
+		}
+		if w.Code != tc.status || w.Header().Get("Content-Type") != middleware.MIMEProblemJSON {
+			t.Errorf("%s: expect %d problem+json, got %d %s", tc.name, tc.status, w.Code, w.Header().Get("Content-Type"))
+		}
+		if problem.Type != tc.typ || problem.Title == "" || problem.Status != tc.status || problem.TraceId != "trace-"+tc.name || len(problem.Errors) != tc.errors {
+			t.Errorf("%s: unexpected problem %s", tc.name, w.Body.String())
+		}
+	}
+}
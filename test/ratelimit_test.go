@@ -0,0 +1,93 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitRoute(t *testing.T) {
+	for _, algorithm := range []middleware.RateLimitAlgorithm{middleware.RATE_LIMIT_TOKEN_BUCKET, middleware.RATE_LIMIT_SLIDING_WINDOW} {
+		engine := gin.New()
+		wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+			RouterGroup:  engine.Group("/public"),
+			RelativePath: "search",
+			NonLogin:     true,
+			RateLimit: &middleware.RateLimitConfig{
+				Limit:     2,
+				Window:    time.Minute,
+				Algorithm: algorithm,
+				Store:     middleware.NewMemoryRateLimitStore(),
+			},
+			BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+				return result.Success("ok")
+			},
+		})
+
+		var w *httptest.ResponseRecorder
+		for i := 0; i < 3; i++ {
+			w = httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public/search", nil))
+			if i < 2 && w.Code != http.StatusOK {
+				t.Fatalf("algorithm %d: request %d should be allowed, got %d", algorithm, i, w.Code)
+			}
+		}
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("algorithm %d: expect 429, got %d", algorithm, w.Code)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("Retry-After") == "" {
+			t.Errorf("algorithm %d: unexpected headers %v", algorithm, w.Header())
+		}
+	}
+}
+
+func TestRateLimitProblemRenderer(t *testing.T) {
+	engine := gin.New()
+	engine.Use(middleware.UseErrorRenderer(&middleware.ProblemErrorRenderer{}))
+	engine.GET("/search", middleware.RateLimitWithConfig(middleware.RateLimitConfig{
+		Limit:  1,
+		Window: time.Minute,
+		Store:  middleware.NewMemoryRateLimitStore(),
+	}), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search", nil))
+	}
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Content-Type") != middleware.MIMEProblemJSON {
+		t.Fatalf("rejection should go through error renderer, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestRateLimitStoreWindows(t *testing.T) {
+	store := middleware.NewMemoryRateLimitStore()
+	hourly := &middleware.RateLimitConfig{Limit: 1, Burst: 1, Window: time.Hour}
+	secondly := &middleware.RateLimitConfig{Limit: 10, Burst: 10, Window: time.Second}
+
+	now := time.Now()
+	if rt, _ := store.Take("report:1", hourly, now); !rt.Allowed {
+		t.Fatal("first hourly request should be allowed")
+	}
+	// 短窗口的路由触发清理时不能删除长窗口路由的状态
+	now = now.Add(5 * time.Second)
+	if rt, _ := store.Take("search:1", secondly, now); !rt.Allowed {
+		t.Fatal("secondly request should be allowed")
+	}
+	rt, _ := store.Take("report:1", hourly, now)
+	if rt.Allowed {
+		t.Fatal("hourly limit should not be lifted by purge of a shorter window")
+	}
+	if rt.RetryAfter < 59*time.Minute {
+		t.Errorf("unexpected retry after %v", rt.RetryAfter)
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/rbac"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRbacEvaluate(t *testing.T) {
+	engine, err := rbac.NewEngineWithPolicy(&rbac.Policy{
+		Roles: []*rbac.RoleDef{
+			{Name: "admin", Inherits: []string{"editor"}},
+			{Name: "editor", Inherits: []string{"viewer"}},
+		},
+		Rules: []*rbac.Rule{
+			{Path: "/admin/**", Allow: []string{"admin"}},
+			{Path: "/order/:id/cancel", Methods: []string{http.MethodPost}, Allow: []string{"editor"}, Deny: []string{"intern"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		roles      []string
+		method     string
+		path       string
+		allowRoles []string
+		allowed    bool
+	}{
+		{[]string{"admin"}, http.MethodGet, "/admin/user/list", nil, true},
+		{[]string{"editor"}, http.MethodGet, "/admin/user/list", nil, false},
+		{[]string{"admin"}, http.MethodPost, "/order/1/cancel", nil, true},
+		{[]string{"editor", "intern"}, http.MethodPost, "/order/1/cancel", nil, false},
+		{[]string{"viewer"}, http.MethodGet, "/order/1/cancel", nil, true},
+		{[]string{"admin"}, http.MethodGet, "/report", []string{"viewer"}, true},
+		{nil, http.MethodGet, "/report", []string{"viewer"}, false},
+	}
+	for _, c := range cases {
+		if d := engine.Evaluate(c.roles, c.method, c.path, c.allowRoles); d.Allowed != c.allowed {
+			t.Errorf("%v %s %s: expect %v, got %+v", c.roles, c.method, c.path, c.allowed, d)
+		}
+	}
+
+	if _, err = rbac.NewEngineWithPolicy(&rbac.Policy{Roles: []*rbac.RoleDef{
+		{Name: "a", Inherits: []string{"b"}},
+		{Name: "b", Inherits: []string{"a"}},
+	}}); err == nil {
+		t.Fatal("expect inheritance cycle error")
+	}
+}
+
+func TestRbacExplainRouteAllowRoles(t *testing.T) {
+	engine := rbac.NewEngine()
+	wrapper.RegisterRbacEngine(engine)
+	defer wrapper.RegisterRbacEngine(rbac.NewEngine())
+
+	ge := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+		RouterGroup:  ge.Group("/rbac"),
+		RelativePath: "order/:id/cancel",
+		AllowRoles:   []string{"editor"},
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+			return result.Success("ok")
+		},
+	})
+	ge.GET("/debug/rbac/explain", engine.ExplainHandler())
+
+	explain := func(query string) string {
+		w := httptest.NewRecorder()
+		ge.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/rbac/explain?"+query, nil))
+		return w.Body.String()
+	}
+
+	// 不传 allowRoles 时按 method、path 查找路由声明的 AllowRoles
+	body := explain("roles=viewer&method=POST&path=/rbac/order/1/cancel")
+	if !strings.Contains(body, `"allowed":false`) || !strings.Contains(body, `"route":"/rbac/order/:id/cancel"`) {
+		t.Errorf("expect denied by route allow roles, got %s", body)
+	}
+	if body = explain("roles=editor&method=POST&path=/rbac/order/1/cancel"); !strings.Contains(body, `"allowed":true`) {
+		t.Errorf("expect allowed by route allow roles, got %s", body)
+	}
+	if body = explain("roles=viewer&method=GET&path=/rbac/order/1/cancel"); !strings.Contains(body, `"allowed":true`) || strings.Contains(body, `"route"`) {
+		t.Errorf("other methods should not match the route, got %s", body)
+	}
+
+	// 之后注册的引擎同样能查到
+	next := rbac.NewEngine()
+	wrapper.RegisterRbacEngine(next)
+	if r := next.MatchRoute(http.MethodPost, "/rbac/order/2/cancel"); r == nil || len(r.AllowRoles) != 1 {
+		t.Errorf("routes should be copied to the new engine, got %+v", r)
+	}
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-web/utils"
+)
+
+type LoginRequest struct {
+	Account  string         `json:"account"`
+	Password string         `json:"password" log:"-"`
+	Mobile   string         `json:"mobile" log:"mask"`
+	IdNo     string         `json:"idNo" log:"hash"`
+	Token    string         `json:"token"`
+	Extra    map[string]any `json:"extra"`
+}
+
+func TestRedact(t *testing.T) {
+	req := &LoginRequest{
+		Account:  "tom",
+		Password: "123456",
+		Mobile:   "13812345678",
+		IdNo:     "110101199001011234",
+		Token:    "abcdefghijklmnop",
+		Extra:    map[string]any{"access_token": "abcdefghijklmnop"},
+	}
+
+	// 敏感字段名整体替换，只有 log:"mask" 标记的字段保留首尾字符
+	expected := `{"account":"tom","extra":{"access_token":"******"},"idNo":"sha256:04e7358a07d7a12d","mobile":"13*******78","token":"******"}`
+	if actual := string(utils.RedactJson(req)); actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+
+	ctx := utils.RedactDgContext(&dgctx.DgContext{Token: "abcdefghijklmnop", ShareToken: "qrstuvwxyz123456"})
+	if ctx.Token != "******" || ctx.ShareToken != "******" {
+		t.Errorf("context tokens should be fully replaced, got %s %s", ctx.Token, ctx.ShareToken)
+	}
+}
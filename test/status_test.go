@@ -0,0 +1,124 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	dghttp "github.com/darwinOrg/go-httpclient"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type StatusRequest struct {
+	Case int    `form:"case"`
+	Name string `form:"name" binding:"required"`
+}
+
+func TestRestStatusTable(t *testing.T) {
+	errs := []*dgerr.DgError{
+		dgerr.ARGUMENT_NOT_VALID,
+		dgerr.NOT_LOGIN_IN,
+		dgerr.NO_PERMISSION,
+		dgerr.SYSTEM_ERROR,
+		{Code: http.StatusNotFound, Message: "not found"},
+		{Code: 100001, Message: "biz error"},
+	}
+
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[StatusRequest, *result.Result[*result.Void]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "status",
+		NonLogin:     true,
+		RestStatus:   true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *StatusRequest) *result.Result[*result.Void] {
+			if req.Case < 0 {
+				return result.SimpleSuccess()
+			}
+			return result.SimpleFailByError(errs[req.Case])
+		},
+	})
+
+	cases := []struct {
+		err    *dgerr.DgError
+		status int
+	}{
+		{errs[0], middleware.RestStatusTable[dgerr.ARGUMENT_NOT_VALID.Code]},
+		{errs[1], middleware.RestStatusTable[dgerr.NOT_LOGIN_IN.Code]},
+		{errs[2], middleware.RestStatusTable[dgerr.NO_PERMISSION.Code]},
+		{errs[3], middleware.RestStatusTable[dgerr.SYSTEM_ERROR.Code]},
+		{errs[4], http.StatusNotFound},
+		{errs[5], middleware.RestUnmappedFailStatus},
+		{nil, http.StatusOK},
+	}
+	for i, tc := range cases {
+		idx := i
+		if tc.err == nil {
+			idx = -1
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public/status?name=a&case="+strconv.Itoa(idx), nil))
+		if w.Code != tc.status {
+			t.Errorf("case %d %v: expect %d, got %d", i, tc.err, tc.status, w.Code)
+		}
+	}
+	for code, status := range map[int]int{
+		dgerr.ARGUMENT_NOT_VALID.Code: http.StatusBadRequest,
+		dgerr.NOT_LOGIN_IN.Code:       http.StatusUnauthorized,
+		dgerr.NO_PERMISSION.Code:      http.StatusForbidden,
+		dgerr.SYSTEM_ERROR.Code:       http.StatusInternalServerError,
+	} {
+		if middleware.RestStatusTable[code] != status {
+			t.Errorf("code %d should map to %d, got %d", code, status, middleware.RestStatusTable[code])
+		}
+	}
+}
+
+func TestRestStatusHints(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamUrl := upstream.URL
+	upstream.Close()
+
+	engine := gin.New()
+	engine.Use(middleware.Recover())
+	wrapper.Get(&wrapper.RequestHolder[StatusRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "hints",
+		NonLogin:     true,
+		RestStatus:   true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *StatusRequest) *result.Result[string] {
+			panic(errors.New("boom"))
+		},
+	})
+	engine.GET("/forward", middleware.RestStatus(), func(c *gin.Context) {
+		wrapper.HttpForward(c, utils.GetDgContext(c), dghttp.Client11, upstreamUrl)
+	})
+	engine.GET("/plain", func(c *gin.Context) {
+		wrapper.HttpForward(c, utils.GetDgContext(c), dghttp.Client11, upstreamUrl)
+	})
+
+	cases := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"bind", "/public/hints", http.StatusBadRequest},
+		{"panic", "/public/hints?name=a", http.StatusInternalServerError},
+		{"forward", "/forward", http.StatusBadGateway},
+		{"forward without rest status", "/plain", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if w.Code != tc.status {
+			t.Errorf("%s: expect %d, got %d %s", tc.name, tc.status, w.Code, w.Body.String())
+		}
+	}
+}
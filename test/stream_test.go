@@ -0,0 +1,91 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type StreamRequest struct {
+	FailAt int `form:"failAt"`
+}
+
+func TestStreamResponse(t *testing.T) {
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[StreamRequest, iter.Seq2[*OrderRow, error]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "orders",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *StreamRequest) iter.Seq2[*OrderRow, error] {
+			return func(yield func(*OrderRow, error) bool) {
+				for i := int64(1); i <= 3; i++ {
+					if i == int64(req.FailAt) {
+						yield(nil, errors.New("db closed"))
+						return
+					}
+					if !yield(&OrderRow{Id: i}, nil) {
+						return
+					}
+				}
+			}
+		},
+	})
+
+	serve := func(url string, accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		request.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	w := serve("/public/orders", "")
+	if !strings.Contains(w.Body.String(), `"success":true,"data":[{"id":1,`) || !strings.HasSuffix(w.Body.String(), `"secret":""}]}`) {
+		t.Errorf("unexpected json stream %s", w.Body.String())
+	}
+
+	w = serve("/public/orders?failAt=3", wrapper.MIMENDJSON)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || lines[2] != `{"streamError":{"code":0,"message":"db closed"}}` {
+		t.Errorf("unexpected ndjson stream %q", w.Body.String())
+	}
+
+	w = serve("/public/orders?failAt=1", "")
+	if strings.Contains(w.Body.String(), "streamError") || !strings.Contains(w.Body.String(), `"message":"db closed"`) {
+		t.Errorf("error before first item should render fail result, got %s", w.Body.String())
+	}
+}
+
+func TestStreamBizLog(t *testing.T) {
+	buf := captureStructuredLog(t)
+
+	engine := gin.New()
+	wrapper.Get(&wrapper.RequestHolder[StreamRequest, iter.Seq[*OrderRow]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "orders",
+		NonLogin:     true,
+		LogLevel:     wrapper.LOG_LEVEL_ALL,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *StreamRequest) iter.Seq[*OrderRow] {
+			return func(yield func(*OrderRow) bool) {
+				yield(&OrderRow{Id: 1})
+			}
+		},
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/public/orders", nil))
+
+	var log map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid biz log %q: %v", buf.String(), err)
+	}
+	if log["result"] != "[stream of *test.OrderRow]" {
+		t.Errorf("stream result should be summarised, got %s", buf.String())
+	}
+}
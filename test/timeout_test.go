@@ -0,0 +1,136 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type SlowRequest struct {
+	Name  string              `form:"name"`
+	Sleep int                 `form:"sleep"`
+	File  *wrapper.UploadFile `form:"file"`
+}
+
+// TestTimeout 需要使用 go test -race 运行，检查超时后被放弃的 BizHandler 与请求日志之间没有数据竞争
+func TestTimeout(t *testing.T) {
+	dir := t.TempDir()
+	abandoned := make(chan string, 1)
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[SlowRequest, *result.Result[string]]{
+		RouterGroup:   engine.Group("/public"),
+		RelativePath:  "slow",
+		NonLogin:      true,
+		RestStatus:    true,
+		Timeout:       100 * time.Millisecond,
+		UploadStorage: wrapper.NewLocalUploadStorage(dir),
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *SlowRequest) *result.Result[string] {
+			time.Sleep(time.Duration(req.Sleep) * time.Millisecond)
+			req.Name = strings.ToUpper(req.Name)
+			if req.Sleep > 0 {
+				f, err := req.File.Open()
+				if err != nil {
+					abandoned <- err.Error()
+					return nil
+				}
+				bs, _ := io.ReadAll(f)
+				_ = f.Close()
+				abandoned <- string(bs)
+			}
+			return result.Success(req.Name)
+		},
+	})
+
+	serve := func(sleep string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("name", "abc")
+		_ = mw.WriteField("sleep", sleep)
+		fw, _ := mw.CreateFormFile("file", "a.txt")
+		_, _ = fw.Write([]byte("hello"))
+		_ = mw.Close()
+
+		request := httptest.NewRequest(http.MethodPost, "/public/slow", &buf)
+		request.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	if w := serve("0"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"data":"ABC"`) {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve("300"); w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expect 504, got %d %s", w.Code, w.Body.String())
+	}
+	// 被放弃的处理逻辑仍然可以读取上传文件，结束后再删除
+	if content := <-abandoned; content != "hello" {
+		t.Fatalf("upload file removed before abandoned handler finished: %s", content)
+	}
+	for i := 0; ; i++ {
+		entries, _ := os.ReadDir(dir)
+		if len(entries) == 0 {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("upload files are not removed after abandoned handler finished: %d", len(entries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+var (
+	timeoutPanicReported = make(chan error, 1)
+	timeoutPanicOnce     sync.Once
+)
+
+func TestTimeoutPanic(t *testing.T) {
+	timeoutPanicOnce.Do(func() {
+		middleware.RegisterRecoverProcessor(func(ctx *dgctx.DgContext, request *http.Request, params map[string]any, err error) {
+			if err.Error() == "panic after timeout" {
+				timeoutPanicReported <- err
+			}
+		})
+	})
+
+	engine := gin.New()
+	engine.Use(middleware.Recover())
+	wrapper.Get(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "slow-panic",
+		NonLogin:     true,
+		RestStatus:   true,
+		Timeout:      50 * time.Millisecond,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+			time.Sleep(200 * time.Millisecond)
+			panic("panic after timeout")
+		},
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public/slow-panic", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expect 504, got %d %s", w.Code, w.Body.String())
+	}
+
+	// 超时后被放弃的处理逻辑 panic 时仍然执行 RecoverProcessor
+	select {
+	case <-timeoutPanicReported:
+	case <-time.After(time.Second):
+		t.Fatal("panic in abandoned handler is not reported")
+	}
+}
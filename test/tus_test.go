@@ -0,0 +1,151 @@
+package test
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type VideoUploadRequest struct {
+	Filename string `form:"filename" binding:"required"`
+}
+
+func TestTusUpload(t *testing.T) {
+	var calls int32
+	engine := gin.New()
+	wrapper.Tus(&wrapper.RequestHolder[VideoUploadRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "videos",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *VideoUploadRequest) *result.Result[string] {
+			atomic.AddInt32(&calls, 1)
+			f, err := wrapper.GetTusUpload(c).Open(c.Request.Context())
+			if err != nil {
+				return result.SimpleFail[string](err.Error())
+			}
+			defer f.Close()
+			bs, _ := io.ReadAll(f)
+			return result.Success(req.Filename + ":" + string(bs))
+		},
+	}, &wrapper.TusConfig{Store: wrapper.NewFileTusStore(t.TempDir())})
+
+	serve := func(method string, url string, body string, headers ...string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Set("Tus-Resumable", wrapper.TusResumable)
+		for i := 0; i+1 < len(headers); i += 2 {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/public/videos", "", "Upload-Length", "10")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("missing required metadata should be rejected, got %d", w.Code)
+	}
+
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("a.mp4"))
+	w = serve(http.MethodPost, "/public/videos", "", "Upload-Length", "10", "Upload-Metadata", metadata)
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusCreated || location == "" {
+		t.Fatalf("create upload failed: %d %s", w.Code, w.Body.String())
+	}
+
+	w = serve(http.MethodPatch, location, "hello", "Content-Type", wrapper.MIMETusOffsetStream, "Upload-Offset", "0")
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "5" {
+		t.Fatalf("first patch failed: %d %s", w.Code, w.Header().Get("Upload-Offset"))
+	}
+
+	w = serve(http.MethodPatch, location, "world", "Content-Type", wrapper.MIMETusOffsetStream, "Upload-Offset", "0")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("offset mismatch should be 409, got %d", w.Code)
+	}
+
+	w = serve(http.MethodHead, location, "")
+	if w.Header().Get("Upload-Offset") != "5" || w.Header().Get("Upload-Length") != "10" {
+		t.Fatalf("unexpected head offset %s", w.Header().Get("Upload-Offset"))
+	}
+
+	w = serve(http.MethodPatch, location, "world", "Content-Type", wrapper.MIMETusOffsetStream, "Upload-Offset", "5")
+	if !strings.Contains(w.Body.String(), `"data":"a.mp4:helloworld"`) {
+		t.Fatalf("complete upload failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// 重复或并发提交最后的 PATCH 不会再次调用 BizHandler
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w := serve(http.MethodPatch, location, "", "Content-Type", wrapper.MIMETusOffsetStream, "Upload-Offset", "10"); w.Code != http.StatusNoContent {
+				t.Errorf("repeated patch should be 204, got %d", w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("biz handler should be called once, got %d", calls)
+	}
+
+	if w = serve(http.MethodDelete, location, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("terminate failed: %d", w.Code)
+	}
+	if w = serve(http.MethodHead, location, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("terminated upload should be 404, got %d", w.Code)
+	}
+}
+
+func TestTusWithCopyBodyAndCors(t *testing.T) {
+	engine := gin.New()
+	engine.Use(middleware.Cors(), middleware.CopyBodyWithConfig(middleware.CopyBodyConfig{MaxContentLen: 16}))
+	wrapper.Tus(&wrapper.RequestHolder[wrapper.EmptyRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "videos",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *wrapper.EmptyRequest) *result.Result[string] {
+			return result.Success("ok")
+		},
+	}, &wrapper.TusConfig{Store: wrapper.NewFileTusStore(t.TempDir())})
+
+	serve := func(method string, url string, body string, headers ...string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Set("Origin", "https://app.example.org")
+		request.Header.Set("Tus-Resumable", wrapper.TusResumable)
+		for i := 0; i+1 < len(headers); i += 2 {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		return w
+	}
+
+	w := serve(http.MethodOptions, "/public/videos", "", "Access-Control-Request-Method", http.MethodPatch,
+		"Access-Control-Request-Headers", "Tus-Resumable, Upload-Offset, Upload-Length, Upload-Metadata")
+	if allow := strings.ToLower(w.Header().Get("Access-Control-Allow-Headers")); !strings.Contains(allow, "upload-offset") || !strings.Contains(allow, "tus-resumable") {
+		t.Fatalf("tus headers should be allowed by cors, got %d %q", w.Code, allow)
+	}
+
+	w = serve(http.MethodPost, "/public/videos", "", "Upload-Length", "100")
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusCreated || location == "" || !strings.Contains(w.Header().Get("Access-Control-Expose-Headers"), "Location") {
+		t.Fatalf("create upload failed: %d %v", w.Code, w.Header())
+	}
+
+	// 超过 CopyBody 限制的分片直接由 tus 读取，不被拒绝
+	w = serve(http.MethodPatch, location, strings.Repeat("a", 64), "Content-Type", wrapper.MIMETusOffsetStream, "Upload-Offset", "0")
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "64" {
+		t.Fatalf("patch larger than copy body limit failed: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+)
+
+type AvatarUploadRequest struct {
+	UserId int64                 `form:"userId" binding:"required"`
+	Avatar *wrapper.UploadFile   `form:"avatar" upload:"maxSize=1KB,mime=image/png|image/jpeg" binding:"required"`
+	Photos []*wrapper.UploadFile `form:"photos" upload:"count=2"`
+}
+
+func TestUploadFile(t *testing.T) {
+	var key string
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[AvatarUploadRequest, *result.Result[string]]{
+		RouterGroup:   engine.Group("/public"),
+		RelativePath:  "avatar",
+		NonLogin:      true,
+		UploadStorage: wrapper.NewLocalUploadStorage(t.TempDir()),
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *AvatarUploadRequest) *result.Result[string] {
+			key = req.Avatar.Key
+			f, _ := req.Avatar.Open()
+			defer f.Close()
+			bs, _ := io.ReadAll(f)
+			return result.Success(req.Avatar.ContentType + "," + string(bs[1:4]))
+		},
+	})
+
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("0", 100)
+	cases := []struct {
+		content string
+		photos  int
+		body    string
+	}{
+		{png, 1, `"data":"image/png,PNG"`},
+		{"plain text", 0, "content type text/plain is not allowed"},
+		{png + strings.Repeat("0", 1024), 0, "exceeds max size 1024 bytes"},
+		{png, 3, "at most 2 files"},
+	}
+	for _, cs := range cases {
+		key = ""
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("userId", "1")
+		fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+		_, _ = fw.Write([]byte(cs.content))
+		for range cs.photos {
+			fw, _ = mw.CreateFormFile("photos", "photo.jpg")
+			_, _ = fw.Write([]byte("photo"))
+		}
+		_ = mw.Close()
+
+		request := httptest.NewRequest(http.MethodPost, "/public/avatar", &buf)
+		request.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+
+		if !strings.Contains(w.Body.String(), cs.body) {
+			t.Errorf("expected %s, got %s", cs.body, w.Body.String())
+		}
+		if key != "" {
+			if _, err := os.Stat(key); !os.IsNotExist(err) {
+				t.Errorf("upload file %s should be removed after request", key)
+			}
+		}
+	}
+}
+
+func TestUploadRequestParams(t *testing.T) {
+	var params map[string]any
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[AvatarUploadRequest, *result.Result[string]]{
+		RouterGroup:   engine.Group("/public"),
+		RelativePath:  "avatar",
+		NonLogin:      true,
+		UploadStorage: wrapper.NewLocalUploadStorage(t.TempDir()),
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, req *AvatarUploadRequest) *result.Result[string] {
+			params = utils.GetAllRequestParams(c, ctx)
+			return result.Success(req.Avatar.ContentType)
+		},
+	})
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("userId", "1")
+	_ = mw.WriteField("source", "app")
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	_, _ = fw.Write([]byte("\x89PNG\r\n\x1a\n"))
+	_ = mw.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/public/avatar?from=test", &buf)
+	request.Header.Set("Content-Type", mw.FormDataContentType())
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	if params["userId"] != "1" || params["source"] != "app" || params["from"] != "test" {
+		t.Errorf("multipart form values should be in request params, got %v", params)
+	}
+	if _, ok := params["avatar"]; ok {
+		t.Errorf("file parts should not be in request params, got %v", params)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/darwinOrg/go-web/wrapper"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SkuRequest struct {
+	Sku       string `json:"sku" binding:"sku"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+func (r *SkuRequest) Validate(ctx *dgctx.DgContext) error {
+	if r.Sku == "SKU-0" {
+		return utils.FieldErrors{{Field: "sku", Tag: "exists", Message: "sku exists"}}
+	}
+	return nil
+}
+
+func TestCustomValidation(t *testing.T) {
+	err := wrapper.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "SKU-")
+	}, map[string]string{"zh": "{0}不是合法的SKU", "en": "{0} is not a valid SKU"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = wrapper.RegisterStructValidation(func(sl validator.StructLevel) {
+		req := sl.Current().Interface().(SkuRequest)
+		if req.EndDate < req.StartDate {
+			sl.ReportError(req.EndDate, "endDate", "EndDate", "afterStartDate", "")
+		}
+	}, SkuRequest{})
+	wrapper.RegisterValidationMessages("afterStartDate", map[string]string{"en": "{0} must be after start date"})
+
+	engine := gin.New()
+	wrapper.Post(&wrapper.RequestHolder[SkuRequest, *result.Result[string]]{
+		RouterGroup:  engine.Group("/public"),
+		RelativePath: "sku",
+		NonLogin:     true,
+		BizHandler: func(c *gin.Context, ctx *dgctx.DgContext, request *SkuRequest) *result.Result[string] {
+			return result.Success(request.Sku)
+		},
+	})
+
+	cases := []struct {
+		body   string
+		errors []*utils.FieldError
+	}{
+		{`{"sku":"x","startDate":"2024-02-01","endDate":"2024-01-01"}`, []*utils.FieldError{
+			{Field: "sku", Tag: "sku", Message: "sku is not a valid SKU"},
+			{Field: "endDate", Tag: "afterStartDate", Message: "endDate must be after start date"},
+		}},
+		{`{"sku":"SKU-0"}`, []*utils.FieldError{{Field: "sku", Tag: "exists", Message: "sku exists"}}},
+		{`{"sku":"SKU-1"}`, nil},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/public/sku", strings.NewReader(c.body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		engine.ServeHTTP(w, r)
+
+		var rt struct {
+			Success bool                `json:"success"`
+			Errors  []*utils.FieldError `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &rt); err != nil {
+			t.Fatal(err)
+		}
+		if rt.Success != (c.errors == nil) {
+			t.Errorf("%s: unexpected response %s", c.body, w.Body.String())
+			continue
+		}
+		expected, _ := json.Marshal(c.errors)
+		actual, _ := json.Marshal(rt.Errors)
+		if string(expected) != string(actual) {
+			t.Errorf("%s: expected errors %s, got %s", c.body, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+)
+
+const (
+	LOG_TAG         = "log"
+	LOG_REDACT_OMIT = "-"
+	LOG_REDACT_MASK = "mask"
+	LOG_REDACT_HASH = "hash"
+	redactMaxDepth  = 32
+	maskedValue     = "******"
+	// logRedactReplace 敏感字段名的默认处理方式，值整体替换为 maskedValue
+	logRedactReplace = "replace"
+)
+
+var (
+	sensitiveKeysLock sync.RWMutex
+	sensitiveKeys     = map[string]bool{}
+)
+
+func init() {
+	RegisterSensitiveKeys("password", "passwd", "pwd", "token", "shareToken", "accessToken", "refreshToken",
+		"secret", "secretKey", "appSecret", "authorization", "cookie", "idCard", "idNo", "idNumber", "bankCard")
+}
+
+// RegisterSensitiveKeys 注册敏感的字段名，大小写、下划线、中划线不敏感，日志中这些字段的值整体替换为 ******
+func RegisterSensitiveKeys(keys ...string) {
+	sensitiveKeysLock.Lock()
+	defer sensitiveKeysLock.Unlock()
+
+	for _, key := range keys {
+		sensitiveKeys[normalizeSensitiveKey(key)] = true
+	}
+}
+
+func IsSensitiveKey(key string) bool {
+	sensitiveKeysLock.RLock()
+	defer sensitiveKeysLock.RUnlock()
+
+	return sensitiveKeys[normalizeSensitiveKey(key)]
+}
+
+func normalizeSensitiveKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// RedactDgContext 返回 Token、ShareToken 整体替换为 ****** 的上下文副本，用于打印日志
+func RedactDgContext(ctx *dgctx.DgContext) *dgctx.DgContext {
+	if ctx == nil {
+		return nil
+	}
+	rc := ctx.Clone()
+	rc.Token = replaceString(rc.Token)
+	rc.ShareToken = replaceString(rc.ShareToken)
+	return rc
+}
+
+// RedactJson 对 v 脱敏后序列化为 JSON，用于打印日志
+func RedactJson(v any) []byte {
+	bs, _ := json.Marshal(Redact(v))
+	return bs
+}
+
+// Redact 按结构体字段的 log 标签和敏感字段名对 v 脱敏，返回可以序列化为 JSON 的副本：
+//
+//	Password string `json:"password" log:"-"`     // 不输出
+//	Mobile   string `json:"mobile" log:"mask"`    // 138****5678
+//	IdNo     string `json:"idNo" log:"hash"`      // sha256:xxxx
+//
+// 字段名或 map 的键命中 RegisterSensitiveKeys 注册的名称时，值整体替换为 ******，不保留任何字符。
+func Redact(v any) any {
+	return redactValue(reflect.ValueOf(v), 0)
+}
+
+func redactValue(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > redactMaxDepth {
+		return nil
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.CanInterface() {
+		switch v.Interface().(type) {
+		case json.Marshaler, encoding.TextMarshaler:
+			return v.Interface()
+		}
+		if v.CanAddr() {
+			switch v.Addr().Interface().(type) {
+			case json.Marshaler, encoding.TextMarshaler:
+				return v.Addr().Interface()
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		mp := map[string]any{}
+		redactStruct(v, mp, depth)
+		return mp
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		mp := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if IsSensitiveKey(key) {
+				mp[key] = redactByMode(iter.Value(), logRedactReplace)
+			} else {
+				mp[key] = redactValue(iter.Value(), depth+1)
+			}
+		}
+		return mp
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		list := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list[i] = redactValue(v.Index(i), depth+1)
+		}
+		return list
+	default:
+		if v.CanInterface() {
+			return v.Interface()
+		}
+		return nil
+	}
+}
+
+func redactStruct(v reflect.Value, mp map[string]any, depth int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+		fv := v.Field(i)
+
+		// 没有指定 json 名称的匿名结构体字段展开到外层，与 encoding/json 保持一致
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				redactStruct(fv, mp, depth+1)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		mode := field.Tag.Get(LOG_TAG)
+		if mode == "" && IsSensitiveKey(name) {
+			mode = logRedactReplace
+		}
+		switch mode {
+		case LOG_REDACT_OMIT:
+			continue
+		case LOG_REDACT_MASK, LOG_REDACT_HASH, logRedactReplace:
+			mp[name] = redactByMode(fv, mode)
+		default:
+			mp[name] = redactValue(fv, depth+1)
+		}
+	}
+}
+
+// omitLogFields 删除 mp 中对应结构体 log:"-" 字段的键（json 或 form 名称）
+func omitLogFields(v any, mp map[string]any) {
+	omitLogFieldsOf(reflect.ValueOf(v), mp)
+}
+
+func omitLogFieldsOf(v reflect.Value, mp map[string]any) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			omitLogFieldsOf(v.Field(i), mp)
+			continue
+		}
+		if field.Tag.Get(LOG_TAG) != LOG_REDACT_OMIT {
+			continue
+		}
+		delete(mp, field.Name)
+		for _, tag := range []string{"json", "form"} {
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" {
+				delete(mp, name)
+			}
+		}
+	}
+}
+
+func redactByMode(v reflect.Value, mode string) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var s string
+	if v.Kind() == reflect.String {
+		s = v.String()
+	} else if v.CanInterface() {
+		bs, _ := json.Marshal(v.Interface())
+		s = string(bs)
+	}
+
+	switch mode {
+	case LOG_REDACT_HASH:
+		return HashString(s)
+	case LOG_REDACT_MASK:
+		return MaskString(s)
+	default:
+		return replaceString(s)
+	}
+}
+
+// replaceString 敏感字段整体替换，不泄露长度和首尾字符
+func replaceString(s string) string {
+	if s == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// MaskString 保留首尾少量字符，中间用 * 代替，较短的字符串全部掩码，用于 log:"mask" 标记的手机号等字段
+func MaskString(s string) string {
+	if s == "" {
+		return ""
+	}
+	rs := []rune(s)
+	n := len(rs)
+	switch {
+	case n <= 6:
+		return maskedValue
+	case n <= 12:
+		return string(rs[:2]) + strings.Repeat("*", n-4) + string(rs[n-2:])
+	default:
+		return string(rs[:3]) + maskedValue + string(rs[n-4:])
+	}
+}
+
+// HashString 返回 sha256 摘要的前 16 位，相同的值在日志中可以关联但无法还原
+func HashString(s string) string {
+	if s == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
@@ -3,11 +3,8 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"maps"
-	"mime"
-	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
@@ -15,6 +12,7 @@ import (
 	dgcoll "github.com/darwinOrg/go-common/collection"
 	"github.com/darwinOrg/go-common/constants"
 	dgctx "github.com/darwinOrg/go-common/context"
+	dgsys "github.com/darwinOrg/go-common/sys"
 	"github.com/darwinOrg/go-common/utils"
 	dghttp "github.com/darwinOrg/go-httpclient"
 	dglogger "github.com/darwinOrg/go-logger"
@@ -24,6 +22,8 @@ import (
 const (
 	DgContextKey          = "DgContext"
 	RequestStructParamKey = "RequestStructParam"
+	BizResultKey          = "BizResult"
+	MultipartValuesKey    = "MultipartValues"
 )
 
 func GetLang(c *gin.Context) string {
@@ -45,21 +45,20 @@ func GetLang(c *gin.Context) string {
 }
 
 func GetAllRequestParams(c *gin.Context, ctx *dgctx.DgContext) map[string]any {
-	body := GetBodyBytes(c)
 	mp := map[string]any{}
 
-	if len(body) > 0 {
-		if c.ContentType() == "multipart/form-data" {
-			err := parseMultipartForm(c, body, mp)
-			if err != nil {
-				dglogger.Errorf(ctx, "form data parse error | body: %s | err: %v", string(body), err)
-			}
-		} else {
-			err := json.Unmarshal(body, &mp)
-			if err != nil {
-				dglogger.Errorf(ctx, "parse request body error | body: %s | err: %v", string(body), err)
+	// multipart 请求体在绑定时流式读取，不能再次读取，使用绑定时记录的非文件表单字段
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		for key, values := range GetMultipartValues(c) {
+			if len(values) > 0 {
+				mp[key] = values[0]
 			}
 		}
+	} else if body := GetBodyBytes(c); len(body) > 0 {
+		err := json.Unmarshal(body, &mp)
+		if err != nil {
+			dglogger.Errorf(ctx, "parse request body error | body length: %d | err: %v", len(body), err)
+		}
 	}
 
 	if len(c.Request.URL.Query()) > 0 {
@@ -70,35 +69,34 @@ func GetAllRequestParams(c *gin.Context, ctx *dgctx.DgContext) map[string]any {
 
 	requestParam := GetRequestStructParam(c)
 	if requestParam != nil {
-		sjmp, _ := utils.StructJsonToMap(requestParam)
-		if sjmp != nil {
+		// 结构体参数按 log 标签脱敏，标记为 log:"-" 的字段同时从原始参数中去掉
+		omitLogFields(requestParam, mp)
+		if sjmp, ok := Redact(requestParam).(map[string]any); ok {
 			maps.Copy(mp, sjmp)
 		}
 	}
 
+	if redacted, ok := Redact(mp).(map[string]any); ok {
+		return redacted
+	}
 	return mp
 }
 
-func parseMultipartForm(c *gin.Context, body []byte, mp map[string]any) error {
-	contentType := c.GetHeader("Content-Type")
-	_, params, err := mime.ParseMediaType(contentType)
-	if err != nil {
-		return err
-	}
-	boundary, ok := params["boundary"]
-	if !ok {
-		return fmt.Errorf("content-type header does not contain boundary parameter")
-	}
-	reader := multipart.NewReader(bytes.NewReader(body), boundary)
-	form, err := reader.ReadForm(32 << 20) // 32 MB max memory
-	if err != nil {
-		return err
-	}
-	for key, values := range form.Value {
-		if len(values) > 0 {
-			mp[key] = values[0]
+// SetMultipartValues 记录流式绑定 multipart 请求时读到的非文件表单字段
+func SetMultipartValues(c *gin.Context, values map[string][]string) {
+	c.Set(MultipartValuesKey, values)
+}
+
+// GetMultipartValues 返回 multipart 请求的非文件表单字段，请求体还没有被绑定时返回 nil
+func GetMultipartValues(c *gin.Context) map[string][]string {
+	if values, ok := c.Get(MultipartValuesKey); ok {
+		if mp, ok := values.(map[string][]string); ok {
+			return mp
 		}
 	}
+	if c.Request.MultipartForm != nil {
+		return c.Request.MultipartForm.Value
+	}
 	return nil
 }
 
@@ -166,6 +164,50 @@ func BuildDgContext(c *gin.Context) *dgctx.DgContext {
 	return ctx
 }
 
+// WriteDgContextHeaders 将 DgContext 写入请求头，与 BuildDgContext 的读取方式保持一致
+func WriteDgContextHeaders(ctx *dgctx.DgContext, header http.Header) {
+	setHeaderIfNotEmpty(header, constants.TraceId, ctx.TraceId)
+	setHeaderIfNotEmpty(header, constants.UID, formatInt64(ctx.UserId))
+	setHeaderIfNotEmpty(header, constants.OpId, formatInt64(ctx.OpId))
+	setHeaderIfNotEmpty(header, constants.RunAs, formatInt64(ctx.RunAs))
+	setHeaderIfNotEmpty(header, constants.Roles, ctx.Roles)
+	setHeaderIfNotEmpty(header, constants.BizTypes, formatInt64(int64(ctx.BizTypes)))
+	setHeaderIfNotEmpty(header, constants.GroupId, formatInt64(ctx.GroupId))
+	setHeaderIfNotEmpty(header, constants.Platform, ctx.Platform)
+	setHeaderIfNotEmpty(header, constants.Lang, ctx.Lang)
+	setHeaderIfNotEmpty(header, constants.Token, ctx.Token)
+	setHeaderIfNotEmpty(header, constants.ShareToken, ctx.ShareToken)
+	setHeaderIfNotEmpty(header, constants.CompanyId, formatInt64(ctx.CompanyId))
+	setHeaderIfNotEmpty(header, constants.Product, formatInt64(int64(ctx.Product)))
+	setHeaderIfNotEmpty(header, constants.Products, joinInts(ctx.Products))
+	setHeaderIfNotEmpty(header, constants.DepartmentIds, joinInts(ctx.DepartmentIds))
+	setHeaderIfNotEmpty(header, constants.Source, ctx.Source)
+	setHeaderIfNotEmpty(header, constants.Since, formatInt64(ctx.Since))
+	setHeaderIfNotEmpty(header, constants.OutUserId, ctx.OutUserId)
+	setHeaderIfNotEmpty(header, constants.Profile, dgsys.GetProfile())
+}
+
+func setHeaderIfNotEmpty(header http.Header, key string, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
+
+func formatInt64(i int64) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.FormatInt(i, 10)
+}
+
+func joinInts[T int | int64](ints []T) string {
+	strs := make([]string, 0, len(ints))
+	for _, i := range ints {
+		strs = append(strs, strconv.FormatInt(int64(i), 10))
+	}
+	return strings.Join(strs, ",")
+}
+
 func GetOrGenerateTraceId(c *gin.Context) string {
 	traceId := GetHeader(c, constants.TraceId)
 	if traceId != "" {
@@ -304,6 +346,15 @@ func GetRequestStructParam(c *gin.Context) any {
 	return req
 }
 
+func SetBizResult(c *gin.Context, rt any) {
+	c.Set(BizResultKey, rt)
+}
+
+// GetBizResult 获取 BizHandler 正常执行后的返回结果，参数绑定失败或接口自行写出响应时不存在
+func GetBizResult(c *gin.Context) (any, bool) {
+	return c.Get(BizResultKey)
+}
+
 func MustRequest(c *gin.Context, ctx *dgctx.DgContext) *http.Request {
 	request, _ := CopyRequest(c, ctx)
 	if request == nil {
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	ve "github.com/darwinOrg/go-validator-ext"
+	"github.com/go-playground/validator/v10"
+)
+
+const defaultValidationLang = "zh"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors 可以作为 error 返回，用于在自定义校验中指定出错的字段
+type FieldErrors []*FieldError
+
+func (fes FieldErrors) Error() string {
+	msgs := make([]string, 0, len(fes))
+	for _, fe := range fes {
+		msgs = append(msgs, fe.Message)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var (
+	validationMessagesLock sync.RWMutex
+	validationMessages     = map[string]map[string]string{}
+)
+
+// RegisterValidationMessages 注册校验规则各语言的错误信息，{0} 替换为字段名，{1} 替换为规则参数
+func RegisterValidationMessages(tag string, messages map[string]string) {
+	validationMessagesLock.Lock()
+	defer validationMessagesLock.Unlock()
+
+	mp, ok := validationMessages[tag]
+	if !ok {
+		mp = map[string]string{}
+		validationMessages[tag] = mp
+	}
+	for lang, msg := range messages {
+		mp[normalizeValidationLang(lang)] = msg
+	}
+}
+
+// TranslateFieldErrors 将校验错误转换为字段级的错误列表，字段名为去掉结构体名称的路径（如 address.city），
+// 不是校验错误时返回 nil
+func TranslateFieldErrors(err error, lang string) []*FieldError {
+	var fes FieldErrors
+	if errors.As(err, &fes) {
+		return fes
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	translated := ve.TranslateError(err, lang)
+	fieldErrors := make([]*FieldError, 0, len(errs))
+	for _, fe := range errs {
+		field := fe.Namespace()
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+		message, ok := customValidationMessage(fe, lang)
+		if !ok {
+			message = translated[fe.Namespace()]
+		}
+		if message == "" {
+			message = fe.Error()
+		}
+		fieldErrors = append(fieldErrors, &FieldError{Field: field, Tag: fe.Tag(), Param: fe.Param(), Message: message})
+	}
+
+	return fieldErrors
+}
+
+// JoinFieldErrorMessages 合并去重后的错误信息，与 ve.TranslateValidateError 的格式保持一致
+func JoinFieldErrorMessages(fieldErrors []*FieldError) string {
+	seen := map[string]bool{}
+	msgs := make([]string, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		if !seen[fe.Message] {
+			seen[fe.Message] = true
+			msgs = append(msgs, fe.Message)
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func customValidationMessage(fe validator.FieldError, lang string) (string, bool) {
+	validationMessagesLock.RLock()
+	defer validationMessagesLock.RUnlock()
+
+	messages, ok := validationMessages[fe.Tag()]
+	if !ok {
+		return "", false
+	}
+	msg, ok := messages[normalizeValidationLang(lang)]
+	if !ok {
+		if msg, ok = messages[defaultValidationLang]; !ok {
+			return "", false
+		}
+	}
+
+	return strings.NewReplacer("{0}", fe.Field(), "{1}", fe.Param()).Replace(msg), true
+}
+
+// normalizeValidationLang en-US、en;q=0.9 等统一为 en
+func normalizeValidationLang(lang string) string {
+	if i := strings.IndexAny(lang, "-_,;"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return defaultValidationLang
+	}
+	return lang
+}
@@ -0,0 +1,480 @@
+package wrapper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/codec/json"
+)
+
+const (
+	DEFAULT_TAG   = "default"
+	NORMALIZE_TAG = "normalize"
+
+	NORMALIZE_NONE     = "-"
+	NORMALIZE_TRIM     = "trim"
+	NORMALIZE_LOWER    = "lower"
+	NORMALIZE_UPPER    = "upper"
+	NORMALIZE_COLLAPSE = "collapse" // 连续的空白合并为一个空格
+)
+
+// DefaultTrimSpace 为 true 时所有字符串字段都去掉首尾空白，字段上的 normalize:"-" 可以关闭
+var DefaultTrimSpace = false
+
+var (
+	durationType  = reflect.TypeOf(time.Duration(0))
+	tagNamesCache sync.Map
+)
+
+// bindRequest 按 query、body、header、path 参数的顺序合并到 req，后面的来源覆盖前面的：
+//   - header 和 path 参数只绑定声明了 header、uri 标签的字段
+//   - 绑定后仍为零值的字段使用 default 标签的值
+//   - 字符串按 normalize 标签规整，最后统一校验一次
+func bindRequest(c *gin.Context, formats []string, storage UploadStorage, req any) error {
+	if err := applyDefaults(reflect.ValueOf(req), true); err != nil {
+		return err
+	}
+	if err := bindQuery(req, c.Request.URL.Query()); err != nil {
+		return err
+	}
+	if err := bindBody(c, formats, storage, req); err != nil {
+		return err
+	}
+	if err := bindDeclared(req, "header", func(name string) []string { return c.Request.Header.Values(name) }); err != nil {
+		return err
+	}
+	if err := bindDeclared(req, "uri", func(name string) []string {
+		if value, ok := c.Params.Get(name); ok {
+			return []string{value}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return finishBind(req)
+}
+
+// finishBind 为绑定时新建的元素设置默认值、规整字符串后统一校验，请求对象本身的默认值在绑定前设置
+func finishBind(req any) error {
+	rv := reflect.ValueOf(req)
+	if err := applyDefaults(rv.Elem(), false); err != nil {
+		return err
+	}
+	normalizeStrings(rv, nil)
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(req)
+}
+
+// bindQuery 支持 ids=1&ids=2、ids[]=1&ids[]=2、filter[status]=open 和 filter[range][min]=1 的写法，
+// 方括号形式的参数绑定到同名的 map 或结构体字段
+func bindQuery(ptr any, values map[string][]string) error {
+	flat := map[string][]string{}
+	nested := map[string]map[string][]string{}
+	for key, vs := range values {
+		key = strings.TrimSuffix(key, "[]")
+		name, sub, ok := splitBracketKey(key)
+		if !ok {
+			flat[key] = append(flat[key], vs...)
+			continue
+		}
+		if nested[name] == nil {
+			nested[name] = map[string][]string{}
+		}
+		nested[name][sub] = append(nested[name][sub], vs...)
+	}
+
+	if err := binding.MapFormWithTag(ptr, flat, "form"); err != nil {
+		return err
+	}
+	if len(nested) == 0 {
+		return nil
+	}
+	return bindNestedQuery(reflect.ValueOf(ptr), nested)
+}
+
+// splitBracketKey filter[range][min] => filter, range[min]
+func splitBracketKey(key string) (name string, sub string, ok bool) {
+	i := strings.IndexByte(key, '[')
+	if i <= 0 {
+		return "", "", false
+	}
+	j := strings.IndexByte(key[i:], ']')
+	if j < 0 {
+		return "", "", false
+	}
+	return key[:i], key[i+1:i+j] + key[i+j+1:], true
+}
+
+func bindNestedQuery(v reflect.Value, nested map[string]map[string][]string) error {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := range v.NumField() {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		if (sf.PkgPath != "" && !sf.Anonymous) || !fv.CanSet() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		sub, ok := nested[name]
+		if !ok {
+			if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+				if err := bindNestedQuery(fv, nested); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		switch fv.Kind() {
+		case reflect.Map:
+			if err := setQueryMap(fv, sub); err != nil {
+				return fmt.Errorf("query %s: %w", name, err)
+			}
+		case reflect.Struct:
+			if err := bindQuery(fv.Addr().Interface(), sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setQueryMap(m reflect.Value, values map[string][]string) error {
+	if m.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	elemType := m.Type().Elem()
+	for key, vs := range values {
+		elem := reflect.New(elemType).Elem()
+		switch {
+		case elemType.Kind() == reflect.String:
+			elem.SetString(vs[len(vs)-1])
+		case elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.String:
+			elem.Set(reflect.ValueOf(vs).Convert(elemType))
+		default:
+			if err := setStringValue(elem, vs[len(vs)-1]); err != nil {
+				return err
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), elem)
+	}
+	return nil
+}
+
+// bindBody 使用路由允许的编码格式解码请求体，表单按 form 标签绑定，未注册的类型忽略请求体
+func bindBody(c *gin.Context, formats []string, storage UploadStorage, req any) error {
+	if c.Request.Method == http.MethodGet || c.Request.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	switch mediaType {
+	case binding.MIMEPOSTForm:
+		if err := c.Request.ParseForm(); err != nil {
+			return err
+		}
+		return binding.MapFormWithTag(req, c.Request.PostForm, "form")
+	case binding.MIMEMultipartPOSTForm:
+		return bindMultipart(c, storage, req)
+	}
+
+	for _, cd := range codecs {
+		if cd.Binding == nil || !containsContentType(cd.ContentTypes, mediaType) {
+			continue
+		}
+		if !formatAllowed(formats, cd.Name) {
+			return errors.New("unsupported content type: " + mediaType)
+		}
+
+		body, err := requestBody(c)
+		if err != nil || len(body) == 0 {
+			return err
+		}
+		if cd.Decode != nil {
+			return cd.Decode(body, req)
+		}
+		return cd.Binding.BindBody(body, req)
+	}
+	return nil
+}
+
+// requestBody 读取并缓存请求体，之后的中间件和 ShouldBindBodyWith 仍然可以读取
+func requestBody(c *gin.Context) ([]byte, error) {
+	if cb, ok := c.Get(gin.BodyBytesKey); ok {
+		if body, ok := cb.([]byte); ok {
+			return body, nil
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(gin.BodyBytesKey, body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// bindDeclared 只把声明了 tag 标签的名称从 lookup 中取值绑定，避免未声明的字段按字段名匹配到请求头
+func bindDeclared(ptr any, tag string, lookup func(name string) []string) error {
+	names := declaredTagNames(reflect.TypeOf(ptr), tag)
+	if len(names) == 0 {
+		return nil
+	}
+
+	form := make(map[string][]string, len(names))
+	for _, name := range names {
+		if vs := lookup(name); len(vs) > 0 {
+			form[name] = vs
+		}
+	}
+	return binding.MapFormWithTag(ptr, form, tag)
+}
+
+func declaredTagNames(t reflect.Type, tag string) []string {
+	type cacheKey struct {
+		t   reflect.Type
+		tag string
+	}
+	key := cacheKey{t: t, tag: tag}
+	if names, ok := tagNamesCache.Load(key); ok {
+		return names.([]string)
+	}
+
+	var names []string
+	collectTagNames(t, tag, map[reflect.Type]bool{}, &names)
+	tagNamesCache.Store(key, names)
+	return names
+}
+
+func collectTagNames(t reflect.Type, tag string, visited map[reflect.Type]bool, names *[]string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if name != "" {
+			*names = append(*names, name)
+		}
+		collectTagNames(sf.Type, tag, visited, names)
+	}
+}
+
+// applyDefaults 为 default 标签的字段设置默认值，切片用逗号分隔。绑定前对请求对象调用，客户端传入的 false、0、空字符串等会覆盖默认值；
+// 绑定后以 created 为 false 再次调用，只处理绑定时新建的切片元素和指针指向的结构体，其中的零值字段视为未传
+func applyDefaults(v reflect.Value, created bool) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return applyDefaults(v.Elem(), true)
+	case reflect.Slice:
+		for i := range v.Len() {
+			if err := applyDefaults(v.Index(i), true); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Array:
+		for i := range v.Len() {
+			if err := applyDefaults(v.Index(i), created); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	t := v.Type()
+	for i := range v.NumField() {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		if (sf.PkgPath != "" && !sf.Anonymous) || !fv.CanSet() {
+			continue
+		}
+
+		if def, ok := sf.Tag.Lookup(DEFAULT_TAG); ok {
+			if created && fv.IsZero() {
+				if err := setStringValue(fv, def); err != nil {
+					return fmt.Errorf("field %s default %q: %w", sf.Name, def, err)
+				}
+			}
+			continue
+		}
+		if err := applyDefaults(fv, created); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setStringValue 把字符串转换为 v 的类型，非基本类型按 JSON 解析
+func setStringValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setStringValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setStringValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return json.API.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+	return nil
+}
+
+// normalizeStrings 按 normalize 标签（trim、lower、upper、collapse，逗号分隔）规整字符串，
+// opts 为外层字段的选项，作用于字符串切片和 map 的值
+func normalizeStrings(v reflect.Value, opts []string) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(normalizeString(v.String(), opts))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			normalizeStrings(v.Index(i), opts)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.SetString(normalizeString(iter.Value().String(), opts))
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range v.NumField() {
+			sf := t.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			tag, ok := sf.Tag.Lookup(NORMALIZE_TAG)
+			if tag == NORMALIZE_NONE {
+				continue
+			}
+			var fieldOpts []string
+			if ok {
+				fieldOpts = strings.Split(tag, ",")
+			}
+			normalizeStrings(v.Field(i), fieldOpts)
+		}
+	}
+}
+
+func normalizeString(s string, opts []string) string {
+	if DefaultTrimSpace {
+		s = strings.TrimSpace(s)
+	}
+	for _, opt := range opts {
+		switch strings.TrimSpace(opt) {
+		case NORMALIZE_TRIM:
+			s = strings.TrimSpace(s)
+		case NORMALIZE_LOWER:
+			s = strings.ToLower(s)
+		case NORMALIZE_UPPER:
+			s = strings.ToUpper(s)
+		case NORMALIZE_COLLAPSE:
+			s = strings.Join(strings.Fields(s), " ")
+		}
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+	"unicode/utf8"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	LOG_SAMPLE_NONE       = -1 // 成功且不慢的请求不打印日志
+	logSummaryMaxDepth    = 2
+	logSummaryMaxStrRunes = 128
+)
+
+var (
+	DefaultLogMaxBytes   = 0 // 参数、结果日志的最大字节数，0 表示不限制
+	DefaultLogSampleRate = 1.0
+)
+
+type bizLogOptions struct {
+	maxBytes int
+	summary  bool
+}
+
+// shouldLogBiz 失败和慢请求总是打印，成功的请求按采样率打印
+func shouldLogBiz[T any, V any](rh *RequestHolder[T, V], rt any, cost time.Duration) bool {
+	if !isSuccessResult(rt) {
+		return true
+	}
+
+	slowThreshold := rh.SlowThreshold
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowThreshold
+	}
+	if slowThreshold > 0 && cost > slowThreshold {
+		return true
+	}
+
+	rate := rh.LogSampleRate
+	if rate == 0 {
+		rate = DefaultLogSampleRate
+	}
+	if rate < 0 {
+		return false
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+func newBizLogOptions[T any, V any](rh *RequestHolder[T, V]) *bizLogOptions {
+	maxBytes := rh.LogMaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultLogMaxBytes
+	}
+	return &bizLogOptions{maxBytes: maxBytes, summary: rh.LogSummary}
+}
+
+func bizLogFields(c *gin.Context, ctx *dgctx.DgContext, rp any, rt any, cost time.Duration, ll LogLevel, opts *bizLogOptions) middleware.LogFields {
+	fields := middleware.LogFields{}.
+		Add("path", c.Request.URL.Path).
+		Add("route", c.FullPath()).
+		Add("method", c.Request.Method).
+		Add("status", c.Writer.Status()).
+		Add("traceId", ctx.TraceId).
+		Add("userId", ctx.UserId).
+		Add("companyId", ctx.CompanyId).
+		Add("costMs", middleware.DurationMillis(cost))
+	if code, success, ok := middleware.ResultCode(rt); ok {
+		fields = fields.Add("code", code).Add("success", success)
+	}
+	if ll == LOG_LEVEL_ALL || ll == LOG_LEVEL_PARAM {
+		fields = fields.Add("params", json.RawMessage(bizLogPayload(rp, opts, false)))
+	}
+	if ll == LOG_LEVEL_ALL || ll == LOG_LEVEL_RETURN {
+		fields = fields.Add("result", json.RawMessage(bizLogPayload(rt, opts, opts.summary)))
+	}
+	return fields
+}
+
+// bizLogPayload 脱敏后序列化，summary 为 true 时只输出结构摘要，超过 maxBytes 时截断。
+// 流式结果在输出时才遍历，不能序列化，只记录元素类型
+func bizLogPayload(v any, opts *bizLogOptions, summary bool) []byte {
+	if sr, ok := streamOf(v); ok {
+		return streamLogPayload(sr)
+	}
+
+	redacted := utils.Redact(v)
+	if summary {
+		redacted = summarizeLogValue(redacted, 0)
+	}
+
+	bs, _ := json.Marshal(redacted)
+	return truncateLogBytes(bs, opts.maxBytes)
+}
+
+func streamLogPayload(sr *StreamResponse) []byte {
+	elem := "unknown"
+	if sr.elemType != nil {
+		elem = sr.elemType.String()
+	}
+	bs, _ := json.Marshal(fmt.Sprintf("[stream of %s]", elem))
+	return bs
+}
+
+// summarizeLogValue 保留前两层的键，数组只输出长度，过长的字符串被截断
+func summarizeLogValue(v any, depth int) any {
+	switch val := v.(type) {
+	case map[string]any:
+		if depth >= logSummaryMaxDepth {
+			return fmt.Sprintf("{%d keys}", len(val))
+		}
+		mp := make(map[string]any, len(val))
+		for k, item := range val {
+			mp[k] = summarizeLogValue(item, depth+1)
+		}
+		return mp
+	case []any:
+		return fmt.Sprintf("[%d items]", len(val))
+	case []byte:
+		return fmt.Sprintf("[%d bytes]", len(val))
+	case string:
+		if utf8.RuneCountInString(val) > logSummaryMaxStrRunes {
+			return string([]rune(val)[:logSummaryMaxStrRunes]) + "..."
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func truncateLogBytes(bs []byte, maxBytes int) []byte {
+	if maxBytes <= 0 || len(bs) <= maxBytes {
+		return bs
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(bs[cut]) {
+		cut--
+	}
+	truncated := make([]byte, 0, cut+48)
+	truncated = append(truncated, bs[:cut]...)
+	return fmt.Appendf(truncated, "...(truncated, total %d bytes)", len(bs))
+}
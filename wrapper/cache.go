@@ -0,0 +1,346 @@
+package wrapper
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-monitor"
+	"github.com/gin-gonic/gin"
+)
+
+type CacheVary int
+
+const (
+	CACHE_VARY_USER_ID    CacheVary = 1
+	CACHE_VARY_COMPANY_ID CacheVary = 2
+	CACHE_VARY_LANG       CacheVary = 3
+	CACHE_VARY_PRODUCT    CacheVary = 4
+)
+
+const (
+	DefaultCacheMaxEntries = 10000
+	CacheStatusHeader      = "X-Cache"
+	cacheHitCounterName    = "server_http_cache_hit_total"
+	cacheMissCounterName   = "server_http_cache_miss_total"
+)
+
+// CacheConfig 缓存 Get 接口的返回结果，缓存键由请求路径、请求参数和 VaryBy 指定的上下文维度组成。
+// 需要登录的接口 VaryBy 为空时默认按用户 id 和公司 id 区分，避免不同用户共享结果。
+// 缓存的结果在多个请求间共享，ReturnResultPostProcessor 不应修改它。
+type CacheConfig struct {
+	TTL    time.Duration
+	VaryBy []CacheVary
+	Tags   []string       // 用于 InvalidateCacheTags 批量失效
+	Store  *ResponseCache // 默认 DefaultResponseCache
+}
+
+var DefaultResponseCache = NewResponseCache(DefaultCacheMaxEntries)
+
+// InvalidateCacheTags 使默认缓存中带有任一标签的结果失效，返回失效的条目数
+func InvalidateCacheTags(tags ...string) int {
+	return DefaultResponseCache.InvalidateTags(tags...)
+}
+
+func callBizHandlerWithCache[T any, V any](c *gin.Context, ctx *dgctx.DgContext, rh *RequestHolder[T, V], req *T) (any, bool) {
+	store := rh.Cache.Store
+	if store == nil {
+		store = DefaultResponseCache
+	}
+
+	called := true
+	rt, hit := store.Load(buildCacheKey(c, ctx, cacheVaryBy(rh.Cache, rh.NonLogin), req), rh.Cache.TTL, rh.Cache.Tags, func() (any, bool) {
+		var v any
+		v, called = callBizHandler(c, ctx, rh, req)
+		return v, called && !c.Writer.Written() && !isFileResponse(v) && !isStreamResponse(v) && isSuccessResult(v)
+	})
+
+	labels := map[string]string{"path": c.FullPath()}
+	if hit {
+		c.Header(CacheStatusHeader, "HIT")
+		_ = monitor.IncCounter(cacheHitCounterName, labels)
+	} else {
+		c.Header(CacheStatusHeader, "MISS")
+		_ = monitor.IncCounter(cacheMissCounterName, labels)
+	}
+
+	return rt, called
+}
+
+func cacheVaryBy(config *CacheConfig, nonLogin bool) []CacheVary {
+	if len(config.VaryBy) == 0 && !nonLogin {
+		return []CacheVary{CACHE_VARY_USER_ID, CACHE_VARY_COMPANY_ID}
+	}
+	return config.VaryBy
+}
+
+func buildCacheKey(c *gin.Context, ctx *dgctx.DgContext, varyBy []CacheVary, req any) string {
+	var sb strings.Builder
+	sb.WriteString(c.Request.URL.Path)
+	for _, vary := range varyBy {
+		sb.WriteByte('|')
+		switch vary {
+		case CACHE_VARY_USER_ID:
+			sb.WriteString("uid=" + strconv.FormatInt(ctx.UserId, 10))
+		case CACHE_VARY_COMPANY_ID:
+			sb.WriteString("cid=" + strconv.FormatInt(ctx.CompanyId, 10))
+		case CACHE_VARY_LANG:
+			sb.WriteString("lang=" + ctx.Lang)
+		case CACHE_VARY_PRODUCT:
+			sb.WriteString("product=" + strconv.Itoa(ctx.Product))
+		}
+	}
+
+	h := sha256.New()
+	writeCacheKeyValue(h, reflect.ValueOf(req))
+	sb.WriteByte('|')
+	sb.WriteString(hex.EncodeToString(h.Sum(nil)))
+
+	return sb.String()
+}
+
+// writeCacheKeyValue 按字段名写入绑定后请求对象的所有导出字段，不受 json 标签影响，
+// uri、header 绑定的字段以及 json:"-" 的字段同样参与缓存键
+func writeCacheKeyValue(w io.Writer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		_, _ = io.WriteString(w, "nil")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			_, _ = io.WriteString(w, "nil")
+			return
+		}
+		writeCacheKeyValue(w, v.Elem())
+	case reflect.Struct:
+		// time.Time 等字段均未导出的类型按文本形式写入
+		if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+			text, _ := tm.MarshalText()
+			_, _ = w.Write(text)
+			return
+		}
+		_, _ = io.WriteString(w, "{")
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			_, _ = io.WriteString(w, t.Field(i).Name+":")
+			writeCacheKeyValue(w, v.Field(i))
+			_, _ = io.WriteString(w, ",")
+		}
+		_, _ = io.WriteString(w, "}")
+	case reflect.Slice, reflect.Array:
+		_, _ = io.WriteString(w, "[")
+		for i := 0; i < v.Len(); i++ {
+			writeCacheKeyValue(w, v.Index(i))
+			_, _ = io.WriteString(w, ",")
+		}
+		_, _ = io.WriteString(w, "]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		_, _ = io.WriteString(w, "{")
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(w, "%v:", k)
+			writeCacheKeyValue(w, v.MapIndex(k))
+			_, _ = io.WriteString(w, ",")
+		}
+		_, _ = io.WriteString(w, "}")
+	case reflect.String:
+		_, _ = io.WriteString(w, strconv.Quote(v.String()))
+	default:
+		_, _ = fmt.Fprint(w, v.Interface())
+	}
+}
+
+// isSuccessResult 只缓存成功的结果，返回值中包含 Success 字段时以其为准
+func isSuccessResult(rt any) bool {
+	v := reflect.ValueOf(rt)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return true
+	}
+
+	f := v.FieldByName("Success")
+	if f.IsValid() && f.Kind() == reflect.Bool {
+		return f.Bool()
+	}
+	return true
+}
+
+type cacheEntry struct {
+	key      string
+	value    any
+	expireAt time.Time
+	tags     []string
+}
+
+type cacheCall struct {
+	wg        sync.WaitGroup
+	value     any
+	cacheable bool
+}
+
+// ResponseCache 带过期时间的 LRU 缓存，支持按标签失效，并发未命中同一个键时只计算一次
+type ResponseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	entries    map[string]*list.Element
+	tagIndex   map[string]map[string]struct{}
+	calls      map[string]*cacheCall
+}
+
+func NewResponseCache(maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    map[string]*list.Element{},
+		tagIndex:   map[string]map[string]struct{}{},
+		calls:      map[string]*cacheCall{},
+	}
+}
+
+func (rc *ResponseCache) Get(key string) (any, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return rc.get(key)
+}
+
+func (rc *ResponseCache) Set(key string, value any, ttl time.Duration, tags []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.set(key, value, ttl, tags)
+}
+
+// Load 返回缓存的结果，未命中时调用 fn 计算。同一个键并发未命中时只有一个请求执行 fn，其余请求等待并共享其结果；
+// fn 返回 false 表示结果不可缓存，此时等待的请求各自执行 fn。
+func (rc *ResponseCache) Load(key string, ttl time.Duration, tags []string, fn func() (any, bool)) (value any, hit bool) {
+	rc.mu.Lock()
+	if v, ok := rc.get(key); ok {
+		rc.mu.Unlock()
+		return v, true
+	}
+	if call, ok := rc.calls[key]; ok {
+		rc.mu.Unlock()
+		call.wg.Wait()
+		if call.cacheable {
+			return call.value, true
+		}
+		v, _ := fn()
+		return v, false
+	}
+	call := &cacheCall{}
+	call.wg.Add(1)
+	rc.calls[key] = call
+	rc.mu.Unlock()
+
+	defer func() {
+		rc.mu.Lock()
+		if call.cacheable {
+			rc.set(key, call.value, ttl, tags)
+		}
+		delete(rc.calls, key)
+		rc.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.value, call.cacheable = fn()
+	return call.value, false
+}
+
+func (rc *ResponseCache) Delete(key string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if el, ok := rc.entries[key]; ok {
+		rc.removeElement(el)
+	}
+}
+
+func (rc *ResponseCache) InvalidateTags(tags ...string) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	count := 0
+	for _, tag := range tags {
+		for key := range rc.tagIndex[tag] {
+			if el, ok := rc.entries[key]; ok {
+				rc.removeElement(el)
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (rc *ResponseCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return rc.ll.Len()
+}
+
+func (rc *ResponseCache) get(key string) (any, bool) {
+	el, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expireAt) {
+		rc.removeElement(el)
+		return nil, false
+	}
+	rc.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (rc *ResponseCache) set(key string, value any, ttl time.Duration, tags []string) {
+	if el, ok := rc.entries[key]; ok {
+		rc.removeElement(el)
+	}
+
+	rc.entries[key] = rc.ll.PushFront(&cacheEntry{key: key, value: value, expireAt: time.Now().Add(ttl), tags: tags})
+	for _, tag := range tags {
+		keys, ok := rc.tagIndex[tag]
+		if !ok {
+			keys = map[string]struct{}{}
+			rc.tagIndex[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for rc.maxEntries > 0 && rc.ll.Len() > rc.maxEntries {
+		rc.removeElement(rc.ll.Back())
+	}
+}
+
+func (rc *ResponseCache) removeElement(el *list.Element) {
+	entry := rc.ll.Remove(el).(*cacheEntry)
+	delete(rc.entries, entry.key)
+	for _, tag := range entry.tags {
+		if keys, ok := rc.tagIndex[tag]; ok {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(rc.tagIndex, tag)
+			}
+		}
+	}
+}
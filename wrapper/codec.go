@@ -0,0 +1,257 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/codec/json"
+	"github.com/gin-gonic/gin/render"
+	"github.com/goccy/go-yaml"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	FORMAT_JSON     = "json"
+	FORMAT_XML      = "xml"
+	FORMAT_YAML     = "yaml"
+	FORMAT_MSGPACK  = "msgpack"
+	FORMAT_PROTOBUF = "protobuf"
+)
+
+// Codec 描述一种请求/响应的编码格式，ContentTypes 的第一个作为响应的 Content-Type
+type Codec struct {
+	Name         string
+	ContentTypes []string
+	Binding      binding.BindingBody
+	Render       func(c *gin.Context, code int, obj any)
+	// Decode 只解码不校验，合并多个来源后统一校验，为空时使用 Binding
+	Decode func(body []byte, obj any) error
+	// CanRender 判断返回值能否使用该格式编码，为空表示都可以
+	CanRender func(obj any) bool
+}
+
+var (
+	codecs       []*Codec
+	DefaultCodec = FORMAT_JSON
+)
+
+func init() {
+	RegisterCodec(&Codec{
+		Name:         FORMAT_JSON,
+		ContentTypes: []string{binding.MIMEJSON},
+		Binding:      binding.JSON,
+		Decode:       decodeJSON,
+		Render: func(c *gin.Context, code int, obj any) {
+			c.JSON(code, obj)
+		},
+	})
+	RegisterCodec(&Codec{
+		Name:         FORMAT_XML,
+		ContentTypes: []string{binding.MIMEXML, binding.MIMEXML2},
+		Binding:      binding.XML,
+		Decode:       xml.Unmarshal,
+		Render: func(c *gin.Context, code int, obj any) {
+			c.Render(code, xmlResult{Data: obj})
+		},
+	})
+	RegisterCodec(&Codec{
+		Name:         FORMAT_YAML,
+		ContentTypes: []string{binding.MIMEYAML2, binding.MIMEYAML},
+		Binding:      binding.YAML,
+		Decode:       yaml.Unmarshal,
+		Render: func(c *gin.Context, code int, obj any) {
+			c.YAML(code, obj)
+		},
+	})
+	RegisterCodec(&Codec{
+		Name:         FORMAT_PROTOBUF,
+		ContentTypes: []string{binding.MIMEPROTOBUF},
+		Binding:      binding.ProtoBuf,
+		Decode: func(body []byte, obj any) error {
+			msg, ok := obj.(proto.Message)
+			if !ok {
+				return errors.New("obj is not ProtoMessage")
+			}
+			return proto.Unmarshal(body, msg)
+		},
+		Render: func(c *gin.Context, code int, obj any) {
+			c.ProtoBuf(code, obj)
+		},
+		CanRender: func(obj any) bool {
+			_, ok := obj.(proto.Message)
+			return ok
+		},
+	})
+}
+
+// RegisterCodec 注册编码格式，同名的格式会被替换
+func RegisterCodec(codec *Codec) {
+	for i, cd := range codecs {
+		if cd.Name == codec.Name {
+			codecs[i] = codec
+			return
+		}
+	}
+	codecs = append(codecs, codec)
+}
+
+func GetCodec(name string) *Codec {
+	for _, cd := range codecs {
+		if cd.Name == name {
+			return cd
+		}
+	}
+	return nil
+}
+
+// renderResult 根据 Accept 请求头在路由允许的格式中选择响应格式，Accept 为空、*/*、application/* 或没有匹配的格式时使用 DefaultCodec，
+// 浏览器等发送 text/html 的客户端同样得到 JSON，不返回 406
+func renderResult(c *gin.Context, formats []string, code int, rt any) {
+	negotiateCodec(c.GetHeader("Accept"), formats, rt).Render(c, code, rt)
+}
+
+func negotiateCodec(accept string, formats []string, rt any) *Codec {
+	defaultCodec := GetCodec(DefaultCodec)
+	if defaultCodec == nil || !formatAllowed(formats, defaultCodec.Name) || !canRender(defaultCodec, rt) {
+		defaultCodec = nil
+		for _, name := range formats {
+			if cd := GetCodec(name); cd != nil && canRender(cd, rt) {
+				defaultCodec = cd
+				break
+			}
+		}
+		if defaultCodec == nil {
+			defaultCodec = GetCodec(FORMAT_JSON)
+		}
+	}
+
+	for _, mediaRange := range parseAccept(accept) {
+		// 通配符优先匹配默认格式
+		if strings.Contains(mediaRange, "*") {
+			for _, ct := range defaultCodec.ContentTypes {
+				if matchMediaRange(mediaRange, ct) {
+					return defaultCodec
+				}
+			}
+		}
+		for _, cd := range codecs {
+			if !formatAllowed(formats, cd.Name) || !canRender(cd, rt) {
+				continue
+			}
+			for _, ct := range cd.ContentTypes {
+				if matchMediaRange(mediaRange, ct) {
+					return cd
+				}
+			}
+		}
+	}
+	return defaultCodec
+}
+
+func canRender(cd *Codec, rt any) bool {
+	return cd.CanRender == nil || cd.CanRender(rt)
+}
+
+func decodeJSON(body []byte, obj any) error {
+	decoder := json.API.NewDecoder(bytes.NewReader(body))
+	if binding.EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(obj)
+}
+
+// formatAllowed 路由没有声明 Formats 时只允许 DefaultCodec，XML、YAML 等格式需要在路由上显式开启
+func formatAllowed(formats []string, name string) bool {
+	if len(formats) == 0 {
+		return name == DefaultCodec
+	}
+	for _, f := range formats {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsContentType(contentTypes []string, mediaType string) bool {
+	for _, ct := range contentTypes {
+		if ct == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAccept 按 q 值从高到低返回 Accept 中的媒体类型，q=0 的类型被忽略
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		value string
+		q     float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		value, params, found := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		q := 1.0
+		if found {
+			for _, param := range strings.Split(params, ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if k == "q" {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						q = f
+					}
+				}
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{value: value, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	values := make([]string, len(ranges))
+	for i, r := range ranges {
+		values[i] = r.value
+	}
+	return values
+}
+
+func matchMediaRange(mediaRange string, contentType string) bool {
+	if mediaRange == "*/*" || mediaRange == contentType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(mediaRange, "/*"); ok {
+		return strings.HasPrefix(contentType, prefix+"/")
+	}
+	return false
+}
+
+// xmlResult 泛型类型的名称（如 Result[...]）不是合法的 XML 元素名，统一使用 result 作为根元素
+type xmlResult struct {
+	Data any
+}
+
+func (r xmlResult) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return xml.NewEncoder(w).EncodeElement(r.Data, xml.StartElement{Name: xml.Name{Local: "result"}})
+}
+
+func (r xmlResult) WriteContentType(w http.ResponseWriter) {
+	render.XML{}.WriteContentType(w)
+}
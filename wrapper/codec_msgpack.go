@@ -0,0 +1,24 @@
+//go:build !nomsgpack
+
+package wrapper
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"github.com/ugorji/go/codec"
+)
+
+func init() {
+	RegisterCodec(&Codec{
+		Name:         FORMAT_MSGPACK,
+		ContentTypes: []string{binding.MIMEMSGPACK, binding.MIMEMSGPACK2},
+		Binding:      binding.MsgPack,
+		Decode: func(body []byte, obj any) error {
+			return codec.NewDecoderBytes(body, new(codec.MsgpackHandle)).Decode(obj)
+		},
+		Render: func(c *gin.Context, code int, obj any) {
+			c.Render(code, render.MsgPack{Data: obj})
+		},
+	})
+}
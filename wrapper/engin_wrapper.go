@@ -23,7 +23,7 @@ func DefaultEngine() *gin.Engine {
 func NewEngine(middlewares ...gin.HandlerFunc) *gin.Engine {
 	e := gin.New()
 	e.UseH2C = true
-	e.MaxMultipartMemory = 8 << 20
+	e.MaxMultipartMemory = DefaultMaxMultipartMemory
 	e.Use(middlewares...)
 	_ = e.SetTrustedProxies(nil)
 	e.HandleMethodNotAllowed = true
@@ -34,5 +34,9 @@ func NewEngine(middlewares ...gin.HandlerFunc) *gin.Engine {
 		log.Printf("405 Method Not Allowed: uri: %s, method: %s", c.Request.URL.Path, c.Request.Method)
 	})
 
+	if shouldMountApiExplorer() {
+		MountApiExplorer(e, ApiExplorer)
+	}
+
 	return e
 }
@@ -0,0 +1,56 @@
+package wrapper
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+
+	"github.com/darwinOrg/go-common/constants"
+	dgsys "github.com/darwinOrg/go-common/sys"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed explorer/index.html
+var apiExplorerHtml []byte
+
+type ApiExplorerConfig struct {
+	Path         string
+	Info         *OpenApiInfo
+	EnableInProd bool
+}
+
+// ApiExplorer 不为空时 NewEngine 会自动挂载接口调试页面，生产环境需要显式设置 EnableInProd
+var ApiExplorer *ApiExplorerConfig
+
+var DefaultApiExplorerPath = "/api-explorer"
+
+// ApiExplorerIsProd 判断是否为生产环境，默认使用 dgsys.IsProd
+var ApiExplorerIsProd = dgsys.IsProd
+
+func MountApiExplorer(e *gin.Engine, config *ApiExplorerConfig) {
+	explorerPath := config.Path
+	if explorerPath == "" {
+		explorerPath = DefaultApiExplorerPath
+	}
+	explorerPath = "/" + strings.Trim(explorerPath, "/")
+
+	e.GET(explorerPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", apiExplorerHtml)
+	})
+	ServeOpenApi(e, explorerPath+"/openapi.json", config.Info)
+	e.GET(explorerPath+"/config.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"headers": gin.H{
+				"uid":      constants.UID,
+				"roles":    constants.Roles,
+				"products": constants.Products,
+				"profile":  constants.Profile,
+			},
+			"profile": myProfile,
+		})
+	})
+}
+
+func shouldMountApiExplorer() bool {
+	return ApiExplorer != nil && (!ApiExplorerIsProd() || ApiExplorer.EnableInProd)
+}
@@ -0,0 +1,430 @@
+package wrapper
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	EXPORT_CSV  = "csv"
+	EXPORT_XLSX = "xlsx"
+
+	ExportQueryKey = "export"
+	MIMECSV        = "text/csv"
+	MIMEXLSX       = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	exportFlushRows   = 500
+	exportTimeLayout  = "2006-01-02 15:04:05"
+	exportSearchDepth = 3
+)
+
+// ExportEvent 一次导出，通过 RegisterExportProcessor 注册的处理器记录审计日志
+type ExportEvent struct {
+	Remark   string
+	Path     string
+	Format   string
+	Filename string
+	Columns  []string
+	Rows     int
+	Cost     time.Duration
+	Err      error
+}
+
+type ExportProcessor func(ctx *dgctx.DgContext, event *ExportEvent)
+
+var exportProcessors []ExportProcessor
+
+func RegisterExportProcessor(processor ExportProcessor) {
+	exportProcessors = append(exportProcessors, processor)
+}
+
+type exportColumn struct {
+	index []int
+	title string
+}
+
+// exportFormat export 查询参数优先，其次是 Accept 请求头，都没有时返回空
+func exportFormat(c *gin.Context) string {
+	switch strings.ToLower(c.Query(ExportQueryKey)) {
+	case EXPORT_CSV:
+		return EXPORT_CSV
+	case EXPORT_XLSX, "excel":
+		return EXPORT_XLSX
+	}
+
+	for _, mediaRange := range parseAccept(c.GetHeader("Accept")) {
+		switch mediaRange {
+		case MIMECSV:
+			return EXPORT_CSV
+		case MIMEXLSX:
+			return EXPORT_XLSX
+		}
+	}
+	return ""
+}
+
+// exportResult 在结果中查找第一个结构体切片（优先 Data 字段），按 CSV 或 XLSX 逐行输出，找不到时不输出
+func exportResult(c *gin.Context, ctx *dgctx.DgContext, remark string, format string, rt any) {
+	rows, ok := findExportRows(reflect.ValueOf(rt), 0)
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	elemType := rows.Type().Elem()
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	columns := exportColumns(elemType, nil, primaryLang(ctx.Lang))
+	titles := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.title
+	}
+
+	event := &ExportEvent{
+		Remark:   remark,
+		Path:     c.Request.URL.Path,
+		Format:   format,
+		Filename: exportFilename(c, remark, format),
+		Columns:  titles,
+		Rows:     rows.Len(),
+	}
+
+	c.Header("Content-Disposition", ContentDisposition(event.Filename, false))
+	c.Header("Cache-Control", "no-store")
+	if format == EXPORT_XLSX {
+		c.Header("Content-Type", MIMEXLSX)
+		c.Status(http.StatusOK)
+		event.Err = writeXlsx(c.Writer, titles, columns, rows)
+	} else {
+		c.Header("Content-Type", MIMECSV+"; charset=utf-8")
+		c.Status(http.StatusOK)
+		event.Err = writeCsv(c.Writer, titles, columns, rows)
+	}
+	event.Cost = time.Since(start)
+
+	if event.Err != nil {
+		dglogger.Errorf(ctx, "export %s error | path: %s | err: %v", format, event.Path, event.Err)
+	}
+	for _, processor := range exportProcessors {
+		processor(ctx, event)
+	}
+}
+
+func findExportRows(v reflect.Value, depth int) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		elemType := v.Type().Elem()
+		for elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() == reflect.Struct && elemType != reflect.TypeOf(time.Time{}) {
+			return v, true
+		}
+	case reflect.Struct:
+		if depth >= exportSearchDepth {
+			return reflect.Value{}, false
+		}
+		if data := v.FieldByName("Data"); data.IsValid() {
+			if rows, ok := findExportRows(data, depth+1); ok {
+				return rows, true
+			}
+		}
+		for i := range v.NumField() {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if rows, ok := findExportRows(v.Field(i), depth+1); ok {
+				return rows, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// exportColumns 列标题依次取 title_<lang>、title、remark、json 标签和字段名，export:"-" 或 json:"-" 的字段不导出
+func exportColumns(t reflect.Type, parent []int, lang string) []*exportColumn {
+	var columns []*exportColumn
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() || sf.Tag.Get("export") == "-" || sf.Tag.Get("json") == "-" {
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct {
+			columns = append(columns, exportColumns(ft, index, lang)...)
+			continue
+		}
+		columns = append(columns, &exportColumn{index: index, title: exportTitle(sf, lang)})
+	}
+	return columns
+}
+
+func exportTitle(sf reflect.StructField, lang string) string {
+	if lang != "" {
+		if title := sf.Tag.Get("title_" + lang); title != "" {
+			return title
+		}
+	}
+	for _, tag := range []string{"title", "remark"} {
+		if title := sf.Tag.Get(tag); title != "" {
+			return title
+		}
+	}
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return sf.Name
+}
+
+// primaryLang en-US、en;q=0.9 等统一为 en
+func primaryLang(lang string) string {
+	if i := strings.IndexAny(lang, "-_,;"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+func exportFilename(c *gin.Context, remark string, format string) string {
+	name := remark
+	if name == "" {
+		path := strings.TrimSuffix(c.Request.URL.Path, "/")
+		name = path[strings.LastIndexByte(path, '/')+1:]
+	}
+	return name + "-" + time.Now().Format("20060102150405") + "." + format
+}
+
+// exportCell 取出第 row 行的列值，嵌入的指针为 nil 时返回无效值
+func exportCell(row reflect.Value, col *exportColumn) reflect.Value {
+	v := row
+	for _, i := range col.index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// formatExportValue number 为 true 时 xlsx 按数字类型写入单元格
+func formatExportValue(v reflect.Value) (s string, number bool) {
+	if !v.IsValid() {
+		return "", false
+	}
+	if v.CanInterface() {
+		switch val := v.Interface().(type) {
+		case time.Time:
+			if val.IsZero() {
+				return "", false
+			}
+			return val.Format(exportTimeLayout), false
+		case fmt.Stringer:
+			return val.String(), false
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), false
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return strconv.FormatFloat(f, 'f', -1, v.Type().Bits()), !math.IsNaN(f) && !math.IsInf(f, 0)
+	default:
+		bs, _ := json.Marshal(v.Interface())
+		return string(bs), false
+	}
+}
+
+// writeCsv 写入 UTF-8 BOM 便于 Excel 识别中文，以 = + - @ 开头的文本加单引号避免公式注入
+func writeCsv(w io.Writer, titles []string, columns []*exportColumn, rows reflect.Value) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(titles); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for i := range rows.Len() {
+		for j, col := range columns {
+			v := exportCell(rows.Index(i), col)
+			s, _ := formatExportValue(v)
+			if v.IsValid() && v.Kind() == reflect.String && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+				s = "'" + s
+			}
+			record[j] = s
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushRows == 0 {
+			if err := flushExport(cw, w); err != nil {
+				return err
+			}
+		}
+	}
+	return flushExport(cw, w)
+}
+
+func flushExport(cw *csv.Writer, w io.Writer) error {
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// writeXlsx 手工生成只有一个工作表的 xlsx，工作表按行流式写入 zip
+func writeXlsx(w io.Writer, titles []string, columns []*exportColumn, rows reflect.Value) error {
+	zw := zip.NewWriter(w)
+	for _, part := range [][2]string{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		fw, err := zw.Create(part[0])
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(fw, part[1]); err != nil {
+			return err
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(fw)
+	_, _ = bw.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	writeRow := func(cells func(j int) (string, bool)) {
+		_, _ = bw.WriteString("<row>")
+		for j := range columns {
+			s, number := cells(j)
+			if number {
+				_, _ = bw.WriteString(`<c><v>` + s + `</v></c>`)
+			} else {
+				_, _ = bw.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+				xmlEscape(bw, s)
+				_, _ = bw.WriteString(`</t></is></c>`)
+			}
+		}
+		_, _ = bw.WriteString("</row>")
+	}
+
+	writeRow(func(j int) (string, bool) { return titles[j], false })
+	for i := range rows.Len() {
+		row := rows.Index(i)
+		writeRow(func(j int) (string, bool) {
+			return formatExportValue(exportCell(row, columns[j]))
+		})
+		if (i+1)%exportFlushRows == 0 {
+			if err = bw.Flush(); err != nil {
+				return err
+			}
+			if err = zw.Flush(); err != nil {
+				return err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+
+	_, _ = bw.WriteString("</sheetData></worksheet>")
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// xmlEscape 转义 XML 特殊字符并去掉 XML 1.0 不允许的控制字符
+func xmlEscape(w *bufio.Writer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			_, _ = w.WriteString("&amp;")
+		case '<':
+			_, _ = w.WriteString("&lt;")
+		case '>':
+			_, _ = w.WriteString("&gt;")
+		case '"':
+			_, _ = w.WriteString("&quot;")
+		case '\t', '\n', '\r':
+			_, _ = w.WriteRune(r)
+		default:
+			if r >= 0x20 && r != 0xFFFE && r != 0xFFFF {
+				_, _ = w.WriteRune(r)
+			}
+		}
+	}
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+)
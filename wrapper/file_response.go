@@ -0,0 +1,222 @@
+package wrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// FileResponse BizHandler 返回该类型时按文件下载输出，不再编码为 JSON。
+// 本地文件、fs.FS 中的文件和 io.ReadSeeker 支持 Range、If-Range 和条件请求，Reader 只能整体输出。
+// V 声明为 *FileResponse 时返回 nil 表示文件不存在，需要返回失败结果时可以把 V 声明为 any。
+type FileResponse struct {
+	Name        string // 下载的文件名，可以包含中文
+	ContentType string // 为空时根据文件名后缀或内容识别
+	Size        int64  // Reader 的长度，小于等于 0 表示未知
+	ModTime     time.Time
+	ETag        string // 为空时根据大小和修改时间生成
+	Inline      bool   // 在浏览器中直接打开，默认作为附件下载
+
+	Path    string        `json:"-"`
+	FS      fs.FS         `json:"-"` // 不为空时从 FS 中读取 Path
+	Content io.ReadSeeker `json:"-"`
+	Reader  io.Reader     `json:"-"`
+}
+
+// FileFromPath 本地文件，name 为空时使用文件名
+func FileFromPath(path string, name string) *FileResponse {
+	return &FileResponse{Path: path, Name: name}
+}
+
+func FileFromFS(fsys fs.FS, path string, name string) *FileResponse {
+	return &FileResponse{FS: fsys, Path: path, Name: name}
+}
+
+func FileFromReadSeeker(content io.ReadSeeker, name string, modTime time.Time) *FileResponse {
+	return &FileResponse{Content: content, Name: name, ModTime: modTime}
+}
+
+func FileFromBytes(data []byte, name string) *FileResponse {
+	sum := sha256.Sum256(data)
+	return &FileResponse{
+		Content: bytes.NewReader(data),
+		Name:    name,
+		ETag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+}
+
+// FileFromReader 不可定位的字节流，size 未知时传 0
+func FileFromReader(r io.Reader, name string, size int64) *FileResponse {
+	return &FileResponse{Reader: r, Name: name, Size: size}
+}
+
+func isFileResponse(rt any) bool {
+	_, ok := rt.(*FileResponse)
+	return ok
+}
+
+// serveFileResponse 输出文件，打开的文件和实现了 io.Closer 的内容在输出后关闭
+func serveFileResponse(c *gin.Context, ctx *dgctx.DgContext, fr *FileResponse) {
+	if fr == nil {
+		fileNotFound(c)
+		return
+	}
+
+	if fr.Path != "" && fr.Content == nil && fr.Reader == nil {
+		f, info, err := openFileResponse(fr)
+		if err != nil {
+			dglogger.Errorf(ctx, "open file response error | path: %s | err: %v", fr.Path, err)
+			if errors.Is(err, fs.ErrNotExist) {
+				fileNotFound(c)
+			} else {
+				middleware.AbortWithResult(c, result.SimpleFailByError(err))
+			}
+			return
+		}
+
+		if fr.Name == "" {
+			fr.Name = info.Name()
+		}
+		if fr.ModTime.IsZero() {
+			fr.ModTime = info.ModTime()
+		}
+		if fr.ETag == "" {
+			fr.ETag = fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+		}
+		if rs, ok := f.(io.ReadSeeker); ok {
+			fr.Content = rs
+		} else {
+			fr.Reader, fr.Size = f, info.Size()
+		}
+	}
+
+	writeFileHeaders(c, fr)
+	switch {
+	case fr.Content != nil:
+		if closer, ok := fr.Content.(io.Closer); ok {
+			defer closer.Close()
+		}
+		http.ServeContent(c.Writer, c.Request, fr.Name, fr.ModTime, fr.Content)
+	case fr.Reader != nil:
+		if closer, ok := fr.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		c.Header("Accept-Ranges", "none")
+		if fr.Size > 0 {
+			c.Header("Content-Length", strconv.FormatInt(fr.Size, 10))
+		}
+		c.Status(http.StatusOK)
+		if c.Request.Method == http.MethodHead {
+			return
+		}
+		if _, err := io.Copy(c.Writer, fr.Reader); err != nil {
+			dglogger.Errorf(ctx, "write file response error | name: %s | err: %v", fr.Name, err)
+		}
+	default:
+		fileNotFound(c)
+	}
+}
+
+func openFileResponse(fr *FileResponse) (fs.File, fs.FileInfo, error) {
+	var (
+		f   fs.File
+		err error
+	)
+	if fr.FS != nil {
+		f, err = fr.FS.Open(strings.TrimPrefix(filepath.ToSlash(fr.Path), "/"))
+	} else {
+		f, err = os.Open(fr.Path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+func writeFileHeaders(c *gin.Context, fr *FileResponse) {
+	contentType := fr.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(fr.Name))
+	}
+	if contentType == "" && fr.Content == nil {
+		contentType = "application/octet-stream"
+	}
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	if fr.ETag != "" {
+		c.Header("ETag", fr.ETag)
+	}
+	if fr.Reader != nil && !fr.ModTime.IsZero() {
+		c.Header("Last-Modified", fr.ModTime.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Content-Disposition", ContentDisposition(fr.Name, fr.Inline))
+}
+
+// ContentDisposition 同时输出 ASCII 的 filename 和 RFC 5987 编码的 filename*，兼容不支持 UTF-8 文件名的客户端
+func ContentDisposition(name string, inline bool) string {
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+	if name == "" {
+		return disposition
+	}
+
+	var fallback, encoded strings.Builder
+	for _, r := range name {
+		if r < 0x80 && r >= 0x20 && r != '"' && r != '\\' {
+			fallback.WriteRune(r)
+		} else {
+			fallback.WriteByte('_')
+		}
+	}
+	for _, b := range []byte(name) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback.String(), encoded.String())
+}
+
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+func fileNotFound(c *gin.Context) {
+	middleware.SetResultStatusHint(c, http.StatusNotFound)
+	middleware.AbortWithResult(c, result.SimpleFailByError(&dgerr.DgError{Code: http.StatusNotFound, Message: "file not found"}))
+}
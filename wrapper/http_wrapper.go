@@ -6,19 +6,21 @@ import (
 	dgctx "github.com/darwinOrg/go-common/context"
 	"github.com/darwinOrg/go-common/result"
 	dghttp "github.com/darwinOrg/go-httpclient"
+	"github.com/darwinOrg/go-web/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 func HttpForward(c *gin.Context, ctx *dgctx.DgContext, hc *dghttp.DgHttpClient, forwardUrl string) {
 	request, err := dghttp.CopyRequest(ctx, c.Request, forwardUrl, c.Request.Body)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(err))
+		middleware.AbortWithResult(c, result.SimpleFailByError(err))
 		return
 	}
 
 	resp, err := hc.DoRequestRaw(ctx, request)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(err))
+		middleware.SetResultStatusHint(c, http.StatusBadGateway)
+		middleware.AbortWithResult(c, result.SimpleFailByError(err))
 		return
 	}
 
@@ -28,7 +30,8 @@ func HttpForward(c *gin.Context, ctx *dgctx.DgContext, hc *dghttp.DgHttpClient,
 func WriteResponse(c *gin.Context, ctx *dgctx.DgContext, response *http.Response) {
 	statusCode, headers, body, err := dghttp.ExtractResponse(ctx, response)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(err))
+		middleware.SetResultStatusHint(c, http.StatusBadGateway)
+		middleware.AbortWithResult(c, result.SimpleFailByError(err))
 		return
 	}
 
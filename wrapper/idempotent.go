@@ -0,0 +1,241 @@
+package wrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyStorePurgeSize = 1024
+)
+
+var DefaultIdempotencyTTL = 24 * time.Hour
+
+var (
+	ErrIdempotencyInFlight     = &dgerr.DgError{Code: http.StatusConflict, Message: "a request with the same idempotency key is being processed"}
+	ErrIdempotencyKeyReused    = &dgerr.DgError{Code: http.StatusUnprocessableEntity, Message: "idempotency key was used with a different request body"}
+	ErrIdempotencyBodyTooLarge = &dgerr.DgError{Code: http.StatusRequestEntityTooLarge, Message: "request body too large for idempotency check"}
+)
+
+// IdempotencyRecord Response 为首次请求写出的原始响应体，重放时按 ContentType 原样返回
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	Status      int
+	ContentType string
+	Response    []byte
+}
+
+// IdempotencyStore 保存幂等键的处理状态，共享存储（如 redis）需要保证 Acquire 的原子性
+type IdempotencyStore interface {
+	// Acquire 占用幂等键，键已存在时返回已有记录且 acquired 为 false
+	Acquire(key string, fingerprint string, ttl time.Duration) (record *IdempotencyRecord, acquired bool, err error)
+	Complete(key string, status int, contentType string, response []byte, ttl time.Duration) error
+	Release(key string) error
+}
+
+var DefaultIdempotencyStore IdempotencyStore = NewMemoryIdempotencyStore()
+
+func IdempotentHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		// GET、HEAD 等安全方法本身是幂等的，不处理
+		if !rh.Idempotent || idempotencyKey == "" || isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		ctx := utils.GetDgContext(c)
+		fingerprint, err := idempotencyFingerprint(c)
+		if err != nil {
+			dglogger.Warnf(ctx, "read idempotent request body error: %v", err)
+			middleware.AbortWithResult(c, result.SimpleFailByError(ErrIdempotencyBodyTooLarge))
+			return
+		}
+		key := fmt.Sprintf("%s:%s:%s", idempotencyScope(c, ctx), c.FullPath(), idempotencyKey)
+
+		record, acquired, err := DefaultIdempotencyStore.Acquire(key, fingerprint, DefaultIdempotencyTTL)
+		if err != nil {
+			dglogger.Errorf(ctx, "acquire idempotency key error: %v", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				dglogger.Warnf(ctx, "idempotency key reused with different body: %s", idempotencyKey)
+				middleware.AbortWithResult(c, result.SimpleFailByError(ErrIdempotencyKeyReused))
+			case !record.Completed:
+				dglogger.Warnf(ctx, "idempotency key in flight: %s", idempotencyKey)
+				middleware.AbortWithResult(c, result.SimpleFailByError(ErrIdempotencyInFlight))
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.Status, record.ContentType, record.Response)
+				c.Abort()
+			}
+			return
+		}
+
+		completed := false
+		defer func() {
+			// 请求未正常产生结果（绑定失败、panic 等）时释放幂等键，允许客户端重试
+			if !completed {
+				if rerr := DefaultIdempotencyStore.Release(key); rerr != nil {
+					dglogger.Errorf(ctx, "release idempotency key error: %v", rerr)
+				}
+			}
+		}()
+
+		rw := &idempotentWriter{ResponseWriter: c.Writer}
+		c.Writer = rw
+		c.Next()
+		c.Writer = rw.ResponseWriter
+
+		if _, ok := utils.GetBizResult(c); !ok || !rw.Written() {
+			return
+		}
+		if err = DefaultIdempotencyStore.Complete(key, rw.Status(), rw.Header().Get("Content-Type"), rw.body.Bytes(), DefaultIdempotencyTTL); err != nil {
+			dglogger.Errorf(ctx, "complete idempotency key error: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyScope 幂等键的归属，未登录（uid 为 0）时按 token 的摘要或客户端 IP 区分调用方，避免不同调用方共用同一个键空间
+func idempotencyScope(c *gin.Context, ctx *dgctx.DgContext) string {
+	scope := fmt.Sprintf("uid=%d,cid=%d", ctx.UserId, ctx.CompanyId)
+	if ctx.UserId != 0 {
+		return scope
+	}
+	if ctx.Token != "" {
+		sum := sha256.Sum256([]byte(ctx.Token))
+		return scope + ",token=" + hex.EncodeToString(sum[:])
+	}
+	return scope + ",ip=" + c.ClientIP()
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// idempotencyFingerprint 由查询参数和请求体计算，未使用 CopyBody 时自行读取请求体并放回
+func idempotencyFingerprint(c *gin.Context) (string, error) {
+	var body []byte
+	if cb, ok := c.Get(gin.BodyBytesKey); ok {
+		body, _ = cb.([]byte)
+	}
+	if body == nil && c.Request.Body != nil && c.Request.Body != http.NoBody {
+		bs, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, middleware.DefaultCopyBodyConfig.MaxContentLen))
+		if err != nil {
+			return "", err
+		}
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(bs))
+		body = bs
+	}
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// idempotentWriter 记录首次请求写出的响应体，用于重放
+type idempotentWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotentWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotentWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+type idempotencyEntry struct {
+	record   *IdempotencyRecord
+	expireAt time.Time
+}
+
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: map[string]*idempotencyEntry{}}
+}
+
+func (s *MemoryIdempotencyStore) Acquire(key string, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expireAt) {
+		return entry.record, false, nil
+	}
+
+	if len(s.entries) >= idempotencyStorePurgeSize {
+		for k, e := range s.entries {
+			if now.After(e.expireAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	s.entries[key] = &idempotencyEntry{
+		record:   &IdempotencyRecord{Fingerprint: fingerprint},
+		expireAt: now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(key string, status int, contentType string, response []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return fmt.Errorf("idempotency key not found: %s", key)
+	}
+	entry.record = &IdempotencyRecord{
+		Fingerprint: entry.record.Fingerprint,
+		Completed:   true,
+		Status:      status,
+		ContentType: contentType,
+		Response:    response,
+	}
+	entry.expireAt = time.Now().Add(ttl)
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,124 @@
+package wrapper
+
+import (
+	"sort"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/gin-gonic/gin"
+)
+
+const interceptorsKey = "Interceptors"
+
+// Invocation 一次业务调用，Request 为绑定后的 *T，拦截器可以直接修改它
+type Invocation struct {
+	C       *gin.Context
+	Ctx     *dgctx.DgContext
+	Request any
+	Remark  string
+}
+
+type Invoker func(inv *Invocation) any
+
+// Interceptor 包围 BizHandler 的拦截器，Order 小的在外层，相同 Order 按 engine、group、路由的注册顺序。
+// 三个函数都是可选的，依次执行 Before、Around（或直接调用下一层）、After：
+//   - Before 返回 stop 为 true 时直接以 rt 作为结果，跳过内层的拦截器和 BizHandler
+//   - Around 可以在调用 next 前后处理，也可以不调用 next 直接返回结果
+//   - After 可以替换返回的结果
+type Interceptor struct {
+	Name   string
+	Order  int
+	Before func(inv *Invocation) (rt any, stop bool)
+	Around func(inv *Invocation, next Invoker) any
+	After  func(inv *Invocation, rt any) any
+}
+
+// UseInterceptors 在 engine 或 router group 上注册拦截器，作用于之后注册的 Get、Post 路由
+//
+//	engine.Use(wrapper.UseInterceptors(&wrapper.Interceptor{Name: "audit", After: audit}))
+func UseInterceptors(interceptors ...*Interceptor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var list []*Interceptor
+		if existing, ok := c.Get(interceptorsKey); ok {
+			list = append(list, existing.([]*Interceptor)...)
+		}
+		c.Set(interceptorsKey, append(list, interceptors...))
+		c.Next()
+	}
+}
+
+// TypedInterceptor 路由级别的拦截器，可以直接访问请求对象 T 和结果 V。
+// 外层的非泛型拦截器直接返回的结果不是 V 类型时，Around 和 After 中得到的是 V 的零值。
+type TypedInterceptor[T any, V any] struct {
+	Name   string
+	Order  int
+	Before func(c *gin.Context, ctx *dgctx.DgContext, req *T) (rt V, stop bool)
+	Around func(c *gin.Context, ctx *dgctx.DgContext, req *T, next HandlerFunc[T, V]) V
+	After  func(c *gin.Context, ctx *dgctx.DgContext, req *T, rt V) V
+}
+
+func (ti *TypedInterceptor[T, V]) toInterceptor() *Interceptor {
+	it := &Interceptor{Name: ti.Name, Order: ti.Order}
+	if ti.Before != nil {
+		it.Before = func(inv *Invocation) (any, bool) {
+			return ti.Before(inv.C, inv.Ctx, inv.Request.(*T))
+		}
+	}
+	if ti.Around != nil {
+		it.Around = func(inv *Invocation, next Invoker) any {
+			return ti.Around(inv.C, inv.Ctx, inv.Request.(*T), func(c *gin.Context, ctx *dgctx.DgContext, req *T) V {
+				rt, _ := next(&Invocation{C: c, Ctx: ctx, Request: req, Remark: inv.Remark}).(V)
+				return rt
+			})
+		}
+	}
+	if ti.After != nil {
+		it.After = func(inv *Invocation, rt any) any {
+			v, _ := rt.(V)
+			return ti.After(inv.C, inv.Ctx, inv.Request.(*T), v)
+		}
+	}
+	return it
+}
+
+func collectInterceptors[T any, V any](c *gin.Context, rh *RequestHolder[T, V]) []*Interceptor {
+	var interceptors []*Interceptor
+	if existing, ok := c.Get(interceptorsKey); ok {
+		interceptors = append(interceptors, existing.([]*Interceptor)...)
+	}
+	for _, ti := range rh.Interceptors {
+		interceptors = append(interceptors, ti.toInterceptor())
+	}
+	sort.SliceStable(interceptors, func(i, j int) bool {
+		return interceptors[i].Order < interceptors[j].Order
+	})
+	return interceptors
+}
+
+// invokeWithInterceptors 按顺序组装拦截器链，core 为最内层的业务调用
+func invokeWithInterceptors(inv *Invocation, interceptors []*Interceptor, core Invoker) any {
+	if len(interceptors) == 0 {
+		return core(inv)
+	}
+
+	it, rest := interceptors[0], interceptors[1:]
+	if it.Before != nil {
+		if rt, stop := it.Before(inv); stop {
+			return rt
+		}
+	}
+
+	next := func(i *Invocation) any {
+		return invokeWithInterceptors(i, rest, core)
+	}
+	var rt any
+	if it.Around != nil {
+		rt = it.Around(inv, next)
+	} else {
+		rt = next(inv)
+	}
+
+	if it.After != nil {
+		rt = it.After(inv, rt)
+	}
+	return rt
+}
@@ -0,0 +1,586 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const OpenApiVersion = "3.1.0"
+
+var DefaultOpenApiInfo = &OpenApiInfo{
+	Title:   "API",
+	Version: "1.0.0",
+}
+
+var openApiDescriptionTags = []string{"remark", "title", "desc"}
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiDoc struct {
+	OpenApi    string                      `json:"openapi"`
+	Info       *OpenApiInfo                `json:"info"`
+	Tags       []*OpenApiTag               `json:"tags,omitempty"`
+	Paths      map[string]*OpenApiPathItem `json:"paths"`
+	Components *OpenApiComponents          `json:"components,omitempty"`
+}
+
+type OpenApiTag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiPathItem struct {
+	Get  *OpenApiOperation `json:"get,omitempty"`
+	Post *OpenApiOperation `json:"post,omitempty"`
+}
+
+type OpenApiOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	OperationId string                      `json:"operationId"`
+	Parameters  []*OpenApiParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenApiResponse `json:"responses"`
+}
+
+type OpenApiParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenApiMediaType `json:"content"`
+}
+
+type OpenApiResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenApiMediaType `json:"content,omitempty"`
+}
+
+type OpenApiMediaType struct {
+	Schema *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiComponents struct {
+	Schemas map[string]*OpenApiSchema `json:"schemas,omitempty"`
+}
+
+type OpenApiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Properties           map[string]*OpenApiSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenApiSchema            `json:"items,omitempty"`
+	AdditionalProperties *OpenApiSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64                  `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64                  `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+}
+
+// ServeOpenApi 在指定路径上提供根据 RequestApis 生成的 OpenAPI 文档
+func ServeOpenApi(routes gin.IRoutes, relativePath string, info *OpenApiInfo) {
+	routes.GET(relativePath, OpenApiHandler(info))
+}
+
+func OpenApiHandler(info *OpenApiInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, BuildOpenApi(info, RequestApis))
+	}
+}
+
+func BuildOpenApi(info *OpenApiInfo, apis []*RequestApi) *OpenApiDoc {
+	if info == nil {
+		info = DefaultOpenApiInfo
+	}
+
+	b := &openApiBuilder{
+		schemas: map[string]*OpenApiSchema{},
+		names:   map[reflect.Type]string{},
+		types:   map[string]reflect.Type{},
+	}
+	doc := &OpenApiDoc{
+		OpenApi: OpenApiVersion,
+		Info:    info,
+		Paths:   map[string]*OpenApiPathItem{},
+	}
+
+	tagSet := map[string]bool{}
+	for _, api := range apis {
+		fullPath := OpenApiPath(api)
+		item := doc.Paths[fullPath]
+		if item == nil {
+			item = &OpenApiPathItem{}
+			doc.Paths[fullPath] = item
+		}
+
+		tag := openApiTagName(api.BasePath)
+		if !tagSet[tag] {
+			tagSet[tag] = true
+			doc.Tags = append(doc.Tags, &OpenApiTag{Name: tag})
+		}
+
+		op := b.buildOperation(api, fullPath)
+		op.Tags = []string{tag}
+		switch api.Method {
+		case http.MethodGet:
+			item.Get = op
+		case http.MethodPost:
+			item.Post = op
+		}
+	}
+
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	if len(b.schemas) > 0 {
+		doc.Components = &OpenApiComponents{Schemas: b.schemas}
+	}
+
+	return doc
+}
+
+// OpenApiPath 返回接口的完整路径，gin 的 :name 和 *name 参数转换为 {name}
+func OpenApiPath(api *RequestApi) string {
+	fullPath := path.Join("/", api.BasePath, api.RelativePath)
+	segments := strings.Split(fullPath, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func openApiTagName(basePath string) string {
+	if basePath == "" {
+		return "/"
+	}
+	return basePath
+}
+
+// OpenApiOperationId 由请求方法和完整路径生成 operationId，例如 GET /public/user/{id} 生成 getPublicUserId
+func OpenApiOperationId(method string, fullPath string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(fullPath, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	return sb.String()
+}
+
+type openApiBuilder struct {
+	schemas map[string]*OpenApiSchema
+	names   map[reflect.Type]string
+	types   map[string]reflect.Type
+}
+
+func (b *openApiBuilder) buildOperation(api *RequestApi, fullPath string) *OpenApiOperation {
+	op := &OpenApiOperation{
+		Summary:     api.Remark,
+		OperationId: OpenApiOperationId(api.Method, fullPath),
+		Responses:   map[string]*OpenApiResponse{},
+	}
+
+	for _, segment := range strings.Split(fullPath, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			op.Parameters = append(op.Parameters, &OpenApiParameter{
+				Name:     segment[1 : len(segment)-1],
+				In:       "path",
+				Required: true,
+				Schema:   &OpenApiSchema{Type: "string"},
+			})
+		}
+	}
+
+	requestType := indirectType(reflect.TypeOf(api.RequestObject))
+	if requestType != nil && requestType.Kind() == reflect.Struct && requestType.NumField() > 0 {
+		for _, parameter := range b.buildParameters(requestType, api.Method == http.MethodGet) {
+			if parameter.In != "path" {
+				op.Parameters = append(op.Parameters, parameter)
+				continue
+			}
+			// 路径参数已根据 fullPath 生成，使用字段的类型和描述替换，路径中不存在的参数忽略
+			for _, p := range op.Parameters {
+				if p.In == "path" && p.Name == parameter.Name {
+					p.Schema, p.Description = parameter.Schema, parameter.Description
+				}
+			}
+		}
+		if api.Method != http.MethodGet {
+			op.RequestBody = &OpenApiRequestBody{
+				Required: true,
+				Content: map[string]*OpenApiMediaType{
+					gin.MIMEJSON: {Schema: b.requestBodySchema(requestType)},
+				},
+			}
+		}
+	}
+
+	response := &OpenApiResponse{Description: "OK"}
+	if responseType := indirectType(reflect.TypeOf(api.ResponseObject)); responseType == reflect.TypeOf(FileResponse{}) {
+		response.Content = map[string]*OpenApiMediaType{
+			"application/octet-stream": {Schema: &OpenApiSchema{Type: "string", Format: "binary"}},
+		}
+	} else if elemType, ok := streamElemType(responseType); ok {
+		response.Content = map[string]*OpenApiMediaType{
+			gin.MIMEJSON: {Schema: &OpenApiSchema{
+				Type: "object",
+				Properties: map[string]*OpenApiSchema{
+					"code":        {Type: "integer", Format: "int32"},
+					"message":     {Type: "string"},
+					"success":     {Type: "boolean"},
+					"data":        {Type: "array", Items: b.schemaOf(elemType)},
+					"streamError": b.schemaOf(reflect.TypeOf(StreamError{})),
+				},
+			}},
+			MIMENDJSON: {Schema: b.schemaOf(elemType)},
+		}
+	} else if responseType != nil {
+		response.Content = map[string]*OpenApiMediaType{
+			gin.MIMEJSON: {Schema: b.schemaOf(responseType)},
+		}
+	}
+	op.Responses[strconv.Itoa(http.StatusOK)] = response
+
+	return op
+}
+
+// buildParameters 声明了 uri、header 标签的字段作为路径参数和请求头，withQuery 为 true 时其余字段作为查询参数
+func (b *openApiBuilder) buildParameters(t reflect.Type, withQuery bool) []*OpenApiParameter {
+	var parameters []*OpenApiParameter
+	walkOpenApiFields(t, func(field reflect.StructField) {
+		in, name := openApiParameterIn(field)
+		if name == "" || in == "query" && !withQuery {
+			return
+		}
+
+		schema := b.schemaOf(field.Type)
+		required := applyBindingRules(schema, field) || in == "path"
+		parameters = append(parameters, &OpenApiParameter{
+			Name:        name,
+			In:          in,
+			Description: openApiDescription(field),
+			Required:    required,
+			Schema:      schema,
+		})
+	})
+	return parameters
+}
+
+func (b *openApiBuilder) schemaOf(t reflect.Type) *OpenApiSchema {
+	t = indirectType(t)
+	if t == nil {
+		return &OpenApiSchema{}
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return &OpenApiSchema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(json.RawMessage{}):
+		return &OpenApiSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenApiSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenApiSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenApiSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenApiSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenApiSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenApiSchema{Type: "string", Format: "byte"}
+		}
+		return &OpenApiSchema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &OpenApiSchema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.buildObjectSchema(t)
+		}
+		return &OpenApiSchema{Ref: "#/components/schemas/" + b.componentName(t)}
+	default:
+		return &OpenApiSchema{}
+	}
+}
+
+func (b *openApiBuilder) componentName(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := openApiSchemaName(t)
+	for i := 2; b.types[name] != nil; i++ {
+		name = openApiSchemaName(t) + strconv.Itoa(i)
+	}
+	b.names[t] = name
+	b.types[name] = t
+	// 先占位，防止自引用的结构体无限递归
+	b.schemas[name] = &OpenApiSchema{Type: "object"}
+	b.schemas[name] = b.buildObjectSchema(t)
+
+	return name
+}
+
+// requestBodySchema 请求体不包含 uri、header 绑定的字段，请求对象中有这些字段时内联生成不含它们的 schema
+func (b *openApiBuilder) requestBodySchema(t reflect.Type) *OpenApiSchema {
+	bound := false
+	walkOpenApiFields(t, func(field reflect.StructField) {
+		bound = bound || isOpenApiBoundField(field)
+	})
+	if !bound {
+		return b.schemaOf(t)
+	}
+	return b.buildFilteredObjectSchema(t, func(field reflect.StructField) bool {
+		return !isOpenApiBoundField(field)
+	})
+}
+
+func isOpenApiBoundField(field reflect.StructField) bool {
+	in, _ := openApiParameterIn(field)
+	return in != "query"
+}
+
+func (b *openApiBuilder) buildObjectSchema(t reflect.Type) *OpenApiSchema {
+	return b.buildFilteredObjectSchema(t, nil)
+}
+
+func (b *openApiBuilder) buildFilteredObjectSchema(t reflect.Type, keep func(field reflect.StructField) bool) *OpenApiSchema {
+	schema := &OpenApiSchema{Type: "object", Properties: map[string]*OpenApiSchema{}}
+	walkOpenApiFields(t, func(field reflect.StructField) {
+		name := openApiFieldName(field, "json")
+		if name == "" || (keep != nil && !keep(field)) {
+			return
+		}
+
+		property := b.schemaOf(field.Type)
+		if strings.Contains(field.Tag.Get("json"), ",string") {
+			property = &OpenApiSchema{Type: "string", Format: property.Format}
+		}
+		if property.Ref != "" {
+			// 3.1 允许 $ref 与其他关键字并列，这里只补充描述
+			property = &OpenApiSchema{Ref: property.Ref}
+		}
+		property.Description = openApiDescription(field)
+		if applyBindingRules(property, field) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	})
+
+	return schema
+}
+
+func walkOpenApiFields(t reflect.Type, fn func(field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			if ft := indirectType(field.Type); ft.Kind() == reflect.Struct {
+				walkOpenApiFields(ft, fn)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fn(field)
+	}
+}
+
+// openApiParameterIn 与 bindRequest 的绑定来源一致，依次检查 uri、header 标签，都没有时为查询参数
+func openApiParameterIn(field reflect.StructField) (in string, name string) {
+	for _, source := range [][2]string{{"uri", "path"}, {"header", "header"}} {
+		if tagValue, ok := field.Tag.Lookup(source[0]); ok {
+			if name = strings.SplitN(tagValue, ",", 2)[0]; name == "-" {
+				name = ""
+			}
+			return source[1], name
+		}
+	}
+	return "query", openApiFieldName(field, "form", "json")
+}
+
+func openApiFieldName(field reflect.StructField, tags ...string) string {
+	for _, tag := range tags {
+		tagValue, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		name := strings.SplitN(tagValue, ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func openApiDescription(field reflect.StructField) string {
+	for _, tag := range openApiDescriptionTags {
+		if desc := field.Tag.Get(tag); desc != "" {
+			return desc
+		}
+	}
+	return ""
+}
+
+func openApiSchemaName(t reflect.Type) string {
+	var sb strings.Builder
+	name := strings.ReplaceAll(t.Name(), "[]", " List ")
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return strings.ContainsRune("[]*, ", r) }) {
+		if i := strings.LastIndex(part, "."); i >= 0 {
+			part = part[i+1:]
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('_')
+		}
+		sb.WriteString(part)
+	}
+	return sb.String()
+}
+
+// applyBindingRules 将 binding 标签中的校验规则转换为 schema 约束，返回字段是否必填
+func applyBindingRules(schema *OpenApiSchema, field reflect.StructField) bool {
+	rules := field.Tag.Get("binding")
+	if rules == "" {
+		return false
+	}
+
+	var required bool
+	target := schema
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			// dive 之后的规则作用于元素
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "min", "gte":
+			setOpenApiBound(target, param, true, false)
+		case "max", "lte":
+			setOpenApiBound(target, param, false, false)
+		case "gt":
+			setOpenApiBound(target, param, true, true)
+		case "lt":
+			setOpenApiBound(target, param, false, true)
+		case "len":
+			setOpenApiBound(target, param, true, false)
+			setOpenApiBound(target, param, false, false)
+		case "minLength":
+			if n, err := strconv.Atoi(param); err == nil {
+				target.MinLength = &n
+			}
+		case "maxLength":
+			if n, err := strconv.Atoi(param); err == nil {
+				target.MaxLength = &n
+			}
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, openApiEnumValue(target, v))
+			}
+		case "email":
+			target.Format = "email"
+		case "url", "uri":
+			target.Format = "uri"
+		case "uuid":
+			target.Format = "uuid"
+		case "ip", "ipv4":
+			target.Format = "ipv4"
+		}
+	}
+
+	return required
+}
+
+func setOpenApiBound(schema *OpenApiSchema, param string, lower bool, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string":
+		i := int(n)
+		if lower {
+			schema.MinLength = &i
+		} else {
+			schema.MaxLength = &i
+		}
+	case "array":
+		i := int(n)
+		if lower {
+			schema.MinItems = &i
+		} else {
+			schema.MaxItems = &i
+		}
+	case "integer", "number":
+		switch {
+		case lower && exclusive:
+			schema.ExclusiveMinimum = &n
+		case lower:
+			schema.Minimum = &n
+		case exclusive:
+			schema.ExclusiveMaximum = &n
+		default:
+			schema.Maximum = &n
+		}
+	}
+}
+
+func openApiEnumValue(schema *OpenApiSchema, v string) any {
+	switch schema.Type {
+	case "integer":
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
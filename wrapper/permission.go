@@ -0,0 +1,114 @@
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+)
+
+type PermissionMatchMode int
+
+const (
+	PERMISSION_MATCH_ANY PermissionMatchMode = 0
+	PERMISSION_MATCH_ALL PermissionMatchMode = 1
+)
+
+const (
+	permissionWildcard        = "*"
+	permissionCacheMaxEntries = 10000
+)
+
+type PermissionChecker interface {
+	GetPermissions(ctx *dgctx.DgContext) ([]string, error)
+}
+
+type PermissionCheckerFunc func(ctx *dgctx.DgContext) ([]string, error)
+
+func (f PermissionCheckerFunc) GetPermissions(ctx *dgctx.DgContext) ([]string, error) {
+	return f(ctx)
+}
+
+var permissionChecker PermissionChecker
+
+func RegisterPermissionChecker(checker PermissionChecker) {
+	permissionChecker = checker
+}
+
+// HasPermissions 判断已授予的权限是否满足所需权限，已授予的权限支持 order:* 形式的通配符
+func HasPermissions(granted []string, needed []string, mode PermissionMatchMode) bool {
+	if len(needed) == 0 {
+		return true
+	}
+
+	for _, n := range needed {
+		matched := false
+		for _, g := range granted {
+			if MatchPermission(g, n) {
+				matched = true
+				break
+			}
+		}
+
+		if mode == PERMISSION_MATCH_ALL && !matched {
+			return false
+		}
+		if mode != PERMISSION_MATCH_ALL && matched {
+			return true
+		}
+	}
+
+	return mode == PERMISSION_MATCH_ALL
+}
+
+// MatchPermission 按冒号分段匹配，* 匹配任意一段，末尾的 * 匹配剩余所有段
+func MatchPermission(granted string, needed string) bool {
+	if granted == needed || granted == permissionWildcard {
+		return true
+	}
+
+	gs := strings.Split(granted, ":")
+	ns := strings.Split(needed, ":")
+	for i, g := range gs {
+		if g == permissionWildcard && i == len(gs)-1 {
+			return len(ns) >= len(gs)
+		}
+		if i >= len(ns) || (g != permissionWildcard && g != ns[i]) {
+			return false
+		}
+	}
+
+	return len(gs) == len(ns)
+}
+
+type cachedPermissionChecker struct {
+	checker PermissionChecker
+	ttl     time.Duration
+	cache   *ResponseCache
+}
+
+// NewCachedPermissionChecker 按 UserId、CompanyId 和 Roles 缓存权限查询结果，最多缓存 10000 个，超出时淘汰最久未使用的
+func NewCachedPermissionChecker(checker PermissionChecker, ttl time.Duration) PermissionChecker {
+	return &cachedPermissionChecker{
+		checker: checker,
+		ttl:     ttl,
+		cache:   NewResponseCache(permissionCacheMaxEntries),
+	}
+}
+
+func (cc *cachedPermissionChecker) GetPermissions(ctx *dgctx.DgContext) ([]string, error) {
+	key := fmt.Sprintf("%d:%d:%s", ctx.UserId, ctx.CompanyId, ctx.Roles)
+
+	var err error
+	permissions, _ := cc.cache.Load(key, cc.ttl, nil, func() (any, bool) {
+		var p []string
+		p, err = cc.checker.GetPermissions(ctx)
+		return p, err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions.([]string), nil
+}
@@ -2,7 +2,9 @@ package wrapper
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
@@ -14,6 +16,8 @@ import (
 	dgsys "github.com/darwinOrg/go-common/sys"
 	dglogger "github.com/darwinOrg/go-logger"
 	ve "github.com/darwinOrg/go-validator-ext"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/rbac"
 	"github.com/darwinOrg/go-web/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -33,6 +37,8 @@ var myProfile = dgsys.GetProfile()
 var (
 	EnableRolesCheck    = true
 	EnableProductsCheck = true
+	// EnablePermissionsCheck 开启时声明了 NeedPermissions 的接口需要通过 RegisterPermissionChecker 注册权限查询，未注册时一律拒绝
+	EnablePermissionsCheck = true
 )
 
 type ReturnResultPostProcessor func(ctx *dgctx.DgContext, request *http.Request, rt any)
@@ -43,6 +49,17 @@ func RegisterReturnResultPostProcessor(processor ReturnResultPostProcessor) {
 	returnResultPostProcessors = append(returnResultPostProcessors, processor)
 }
 
+var rbacEngine = rbac.NewEngine()
+
+// RegisterRbacEngine 注册角色策略引擎，CheckRolesHandler 会结合路由的 AllowRoles 和策略规则进行校验。
+// 之前已注册的路由会同步到新引擎，ExplainHandler 可以查到它们的 AllowRoles
+func RegisterRbacEngine(engine *rbac.Engine) {
+	for _, r := range rbacEngine.Routes() {
+		engine.RegisterRoute(r.Method, r.Path, r.AllowRoles)
+	}
+	rbacEngine = engine
+}
+
 var DefaultSlowThreshold = 10 * time.Second
 
 type SlowThresholdProcessor func(ctx *dgctx.DgContext, request *http.Request, remark string, req any, slowThreshold, cost time.Duration)
@@ -73,11 +90,25 @@ type RequestHolder[T any, V any] struct {
 	AllowRoles       []string
 	AllowProducts    []int
 	NeedPermissions  []string
+	PermissionMatch  PermissionMatchMode
 	BizHandler       HandlerFunc[T, V]
 	LogLevel         LogLevel
+	LogMaxBytes      int     // 参数、结果日志的最大字节数，默认 DefaultLogMaxBytes，负数表示不限制
+	LogSampleRate    float64 // 成功请求的日志采样率，默认 DefaultLogSampleRate，LOG_SAMPLE_NONE 表示不打印
+	LogSummary       bool    // 结果日志只打印摘要（数组长度、前两层的键）
 	NotLogSQL        bool
 	EnableTracer     bool
 	SlowThreshold    time.Duration
+	Timeout          time.Duration
+	RateLimit        *middleware.RateLimitConfig
+	Idempotent       bool // 根据 Idempotency-Key 请求头对重复提交直接返回首次的响应，GET 等安全方法不处理
+	Cache            *CacheConfig
+	Formats          []string // 允许的请求/响应编码格式，默认只允许 DefaultCodec（JSON）
+	RestStatus       bool     // 失败结果按 middleware.RestStatusTable 返回 HTTP 状态码
+	ErrorRenderer    middleware.ErrorRenderer
+	Interceptors     []*TypedInterceptor[T, V]
+	UploadStorage    UploadStorage // *UploadFile 字段的存储，默认 DefaultUploadStorage
+	Export           bool          // 结果中的列表可以通过 export 参数或 Accept 导出为 CSV、XLSX
 }
 
 type EmptyRequest struct{}
@@ -86,21 +117,48 @@ type HandlerFunc[T any, V any] func(c *gin.Context, dc *dgctx.DgContext, request
 
 func Get[T any, V any](rh *RequestHolder[T, V]) {
 	rh.GET(rh.RelativePath, BuildHandlersChain(rh)...)
+	registerRbacRoute(rh, http.MethodGet, rh.RelativePath)
 	AppendRequestApi(rh, http.MethodGet)
 }
 
 func Post[T any, V any](rh *RequestHolder[T, V]) {
 	rh.POST(rh.RelativePath, BuildHandlersChain(rh)...)
+	registerRbacRoute(rh, http.MethodPost, rh.RelativePath)
 	AppendRequestApi(rh, http.MethodPost)
 }
 
+// registerRbacRoute 把路由的 AllowRoles 记录到角色策略引擎，供 ExplainHandler 查找
+func registerRbacRoute[T any, V any](rh *RequestHolder[T, V], method string, relativePath string) {
+	rbacEngine.RegisterRoute(method, path.Join("/", rh.BasePath(), relativePath), rh.AllowRoles)
+}
+
 func BuildHandlersChain[T any, V any](rh *RequestHolder[T, V]) gin.HandlersChain {
+	handlersChain := buildAccessHandlersChain(rh)
+	if rh.Idempotent {
+		handlersChain = append(handlersChain, IdempotentHandler(rh))
+	}
+	handlersChain = append(handlersChain, BizHandler(rh))
+	return handlersChain
+}
+
+// buildAccessHandlersChain 登录、限流、产品、角色、权限等 BizHandler 之前的检查
+func buildAccessHandlersChain[T any, V any](rh *RequestHolder[T, V]) gin.HandlersChain {
 	var handlersChain []gin.HandlerFunc
+	if rh.RestStatus {
+		handlersChain = append(handlersChain, middleware.RestStatus())
+	}
+	if rh.ErrorRenderer != nil {
+		handlersChain = append(handlersChain, middleware.UseErrorRenderer(rh.ErrorRenderer))
+	}
 	if len(rh.PreHandlersChain) > 0 {
 		handlersChain = append(handlersChain, rh.PreHandlersChain...)
 	}
 
-	handlersChain = append(handlersChain, LoginHandler(rh), CheckProductHandler(rh), CheckRolesHandler(rh), CheckProfileHandler(), BizHandler(rh))
+	handlersChain = append(handlersChain, LoginHandler(rh))
+	if rh.RateLimit != nil {
+		handlersChain = append(handlersChain, middleware.RateLimitWithConfig(*rh.RateLimit))
+	}
+	handlersChain = append(handlersChain, CheckProductHandler(rh), CheckRolesHandler(rh), CheckPermissionsHandler(rh), CheckProfileHandler())
 	return handlersChain
 }
 
@@ -114,7 +172,7 @@ func LoginHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
 		ctx := utils.GetDgContext(c)
 		if ctx.UserId == 0 {
 			dglogger.Warn(ctx, "not login in")
-			c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(dgerr.NOT_LOGIN_IN))
+			middleware.AbortWithResult(c, result.SimpleFailByError(dgerr.NOT_LOGIN_IN))
 			return
 		}
 
@@ -133,7 +191,7 @@ func CheckProfileHandler() gin.HandlerFunc {
 		if values[0] != myProfile {
 			ctx := utils.GetDgContext(c)
 			dglogger.Warnf(ctx, "invalid profile, your profile is %s, current profile is %s", values[0], myProfile)
-			c.AbortWithStatusJSON(http.StatusOK, result.SimpleFail[string]("your call incorrect profile"))
+			middleware.AbortWithResult(c, result.SimpleFail[string]("your call incorrect profile"))
 			return
 		}
 
@@ -143,23 +201,48 @@ func CheckProfileHandler() gin.HandlerFunc {
 
 func CheckRolesHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !EnableRolesCheck || len(rh.AllowRoles) == 0 {
+		if !EnableRolesCheck || !rbacEngine.NeedCheck(c.Request.Method, c.Request.URL.Path, rh.AllowRoles) {
+			c.Next()
+			return
+		}
+
+		ctx := utils.GetDgContext(c)
+		decision := rbacEngine.Evaluate(rbac.SplitRoles(ctx.Roles), c.Request.Method, c.Request.URL.Path, rh.AllowRoles)
+		if !decision.Allowed {
+			dglogger.Warnf(ctx, "has no allowed roles: %s", strings.Join(decision.Reasons, "; "))
+			middleware.AbortWithResult(c, result.SimpleFailByError(dgerr.NO_PERMISSION))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func CheckPermissionsHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !EnablePermissionsCheck || len(rh.NeedPermissions) == 0 {
 			c.Next()
 			return
 		}
 
 		ctx := utils.GetDgContext(c)
-		if ctx.Roles == "" {
-			dglogger.Warn(ctx, "has no roles")
-			c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(dgerr.NO_PERMISSION))
+		if permissionChecker == nil {
+			dglogger.Errorf(ctx, "no permission checker registered, deny %s %s which needs permissions %v", c.Request.Method, c.FullPath(), rh.NeedPermissions)
+			middleware.AbortWithResult(c, result.SimpleFailByError(dgerr.NO_PERMISSION))
 			return
 		}
 
-		roles := strings.Split(ctx.Roles, ",")
-		dgcoll.Intersection(roles, rh.AllowRoles)
-		if !dgcoll.ContainsAny(roles, rh.AllowRoles) {
-			dglogger.Warn(ctx, "has no allowed roles")
-			c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(dgerr.NO_PERMISSION))
+		permissions, err := permissionChecker.GetPermissions(ctx)
+		if err != nil {
+			// 内部错误只记录日志，不返回给客户端
+			dglogger.Errorf(ctx, "get permissions error: %v", err)
+			middleware.AbortWithResult(c, result.SimpleFailByError(dgerr.NO_PERMISSION))
+			return
+		}
+
+		if !HasPermissions(permissions, rh.NeedPermissions, rh.PermissionMatch) {
+			dglogger.Warnf(ctx, "has no permissions: %v", rh.NeedPermissions)
+			middleware.AbortWithResult(c, result.SimpleFailByError(dgerr.NO_PERMISSION))
 			return
 		}
 
@@ -177,14 +260,14 @@ func CheckProductHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc
 		ctx := utils.GetDgContext(c)
 		if len(ctx.Products) == 0 {
 			dglogger.Warn(ctx, "has no products")
-			c.AbortWithStatusJSON(http.StatusOK, result.FailByError[*result.Void](dgerr.NO_PERMISSION))
+			middleware.AbortWithResult(c, result.FailByError[*result.Void](dgerr.NO_PERMISSION))
 			return
 		}
 
 		intersectionProducts := dgcoll.Intersection(ctx.Products, rh.AllowProducts)
 		if len(intersectionProducts) == 0 {
 			dglogger.Warn(ctx, "has no allowed products")
-			c.AbortWithStatusJSON(http.StatusOK, result.FailByError[*result.Void](dgerr.NO_PERMISSION))
+			middleware.AbortWithResult(c, result.FailByError[*result.Void](dgerr.NO_PERMISSION))
 			return
 		}
 		ctx.Product = intersectionProducts[0]
@@ -195,6 +278,7 @@ func CheckProductHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc
 
 func BizHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		defer removeUploadFiles(c)
 		start := time.Now()
 		if rh.LogLevel == 0 {
 			rh.LogLevel = DEFAULT_LOG_LEVEL
@@ -205,17 +289,19 @@ func BizHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
 		ctx.EnableTracer = rh.EnableTracer
 
 		var rt any
+		bizCalled := false
 		req := new(T)
-		if err := c.ShouldBind(req); err != nil {
+		err := bindRequest(c, rh.Formats, rh.UploadStorage, req)
+		if err == nil {
+			err = validateRequest(ctx, req)
+		}
+		if err != nil {
 			dglogger.Errorf(ctx, "bind request object error: %v", err)
-			errMsg := ve.TranslateValidateError(err, ctx.Lang)
-			if errMsg != "" {
-				rt = result.SimpleFailByError(dgerr.SimpleDgError(errMsg))
-			} else {
-				rt = result.SimpleFailByError(err)
-			}
+			middleware.SetResultStatusHint(c, http.StatusBadRequest)
+			middleware.SetBindError(c, err)
+			rt = bindFailResult(ctx, err)
 		} else {
-			rt = rh.BizHandler(c, ctx, req)
+			rt, bizCalled = invokeBizHandler(c, ctx, rh, req)
 		}
 		utils.SetRequestStructParam(c, req)
 
@@ -234,30 +320,92 @@ func BizHandler[T any, V any](rh *RequestHolder[T, V]) gin.HandlerFunc {
 			}
 		}
 
-		if rh.LogLevel != LOG_LEVEL_NONE {
-			printBizHandlerLog(c, ctx, req, rt, cost, rh.LogLevel)
+		if rh.Export && bizCalled && isSuccessResult(rt) && !c.Writer.Written() {
+			if format := exportFormat(c); format != "" {
+				exportResult(c, ctx, rh.Remark, format, rt)
+			}
 		}
 
-		if !c.Writer.Written() {
-			c.JSON(http.StatusOK, rt)
+		// 参数校验失败、超时等没有得到业务结果的情况交给自定义的 ErrorRenderer 输出
+		if _, ok := middleware.GetErrorRenderer(c).(middleware.ResultErrorRenderer); !ok && !bizCalled {
+			middleware.AbortWithResult(c, rt)
+		} else if fr, ok := rt.(*FileResponse); ok && !c.Writer.Written() {
+			serveFileResponse(c, ctx, fr)
+		} else if sr, ok := streamOf(rt); ok && !c.Writer.Written() {
+			serveStream(c, ctx, rh.Formats, sr)
+		} else if !c.Writer.Written() {
+			renderResult(c, rh.Formats, middleware.ResultStatus(c, rt), rt)
+			if bizCalled {
+				utils.SetBizResult(c, rt)
+			}
+		}
+
+		// 输出之后打印日志，以便记录实际的响应状态码
+		if rh.LogLevel != LOG_LEVEL_NONE && shouldLogBiz(rh, rt, cost) {
+			printBizHandlerLog(c, ctx, req, rt, cost, rh.LogLevel, newBizLogOptions(rh))
 		}
 
 		c.Next()
 	}
 }
 
-func printBizHandlerLog[T any](c *gin.Context, ctx *dgctx.DgContext, rp *T, rt any, cost time.Duration, ll LogLevel) {
-	ctxJson, _ := json.Marshal(ctx)
+func bindFailResult(ctx *dgctx.DgContext, err error) any {
+	if fieldErrors := utils.TranslateFieldErrors(err, ctx.Lang); len(fieldErrors) > 0 {
+		errMsg := utils.JoinFieldErrorMessages(fieldErrors)
+		return &ValidateFailResult{Result: result.SimpleFailByError(dgerr.SimpleDgError(errMsg)), Errors: fieldErrors}
+	}
+	var dgErr *dgerr.DgError
+	if errors.As(err, &dgErr) {
+		return result.SimpleFailByError(dgErr)
+	}
+	if errMsg := ve.TranslateValidateError(err, ctx.Lang); errMsg != "" {
+		return result.SimpleFailByError(dgerr.SimpleDgError(errMsg))
+	}
+	return result.SimpleFailByError(err)
+}
+
+// invokeBizHandler 依次经过拦截器、缓存调用 BizHandler，拦截器直接返回结果时 completed 为 true
+func invokeBizHandler[T any, V any](c *gin.Context, ctx *dgctx.DgContext, rh *RequestHolder[T, V], req *T) (rt any, completed bool) {
+	completed = true
+	core := func(inv *Invocation) any {
+		var r any
+		if rh.Cache != nil && rh.Cache.TTL > 0 && inv.C.Request.Method == http.MethodGet {
+			r, completed = callBizHandlerWithCache(inv.C, inv.Ctx, rh, inv.Request.(*T))
+		} else {
+			r, completed = callBizHandler(inv.C, inv.Ctx, rh, inv.Request.(*T))
+		}
+		return r
+	}
+
+	inv := &Invocation{C: c, Ctx: ctx, Request: req, Remark: rh.Remark}
+	rt = invokeWithInterceptors(inv, collectInterceptors(c, rh), core)
+	return rt, completed
+}
+
+func callBizHandler[T any, V any](c *gin.Context, ctx *dgctx.DgContext, rh *RequestHolder[T, V], req *T) (any, bool) {
+	if rh.Timeout > 0 {
+		return callBizHandlerWithTimeout(c, ctx, rh, req)
+	}
+	return rh.BizHandler(c, ctx, req), true
+}
+
+func printBizHandlerLog[T any](c *gin.Context, ctx *dgctx.DgContext, rp *T, rt any, cost time.Duration, ll LogLevel, opts *bizLogOptions) {
+	if middleware.DefaultLogFormat != middleware.LOG_FORMAT_TEXT {
+		middleware.LogStructured(ctx, "biz", bizLogFields(c, ctx, rp, rt, cost, ll, opts))
+		return
+	}
+
+	ctxJson, _ := json.Marshal(utils.RedactDgContext(ctx))
 
 	if ll == LOG_LEVEL_ALL {
-		rpBytes, _ := json.Marshal(rp)
-		rtBytes, _ := json.Marshal(rt)
+		rpBytes := bizLogPayload(rp, opts, false)
+		rtBytes := bizLogPayload(rt, opts, opts.summary)
 		dglogger.Infof(ctx, "path: %s, context: %s, params: %s, result: %s, cost: %13v", c.Request.URL.Path, ctxJson, rpBytes, rtBytes, cost)
 	} else if ll == LOG_LEVEL_PARAM {
-		rpBytes, _ := json.Marshal(rp)
+		rpBytes := bizLogPayload(rp, opts, false)
 		dglogger.Infof(ctx, "path: %s, context: %s, params: %s, cost: %13v", c.Request.URL.Path, ctxJson, rpBytes, cost)
 	} else if ll == LOG_LEVEL_RETURN {
-		rtBytes, _ := json.Marshal(rt)
+		rtBytes := bizLogPayload(rt, opts, opts.summary)
 		dglogger.Infof(ctx, "path: %s, context: %s, result: %s, cost: %13v", c.Request.URL.Path, ctxJson, rtBytes, cost)
 	}
 }
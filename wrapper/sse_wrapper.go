@@ -9,6 +9,7 @@ import (
 	dgctx "github.com/darwinOrg/go-common/context"
 	"github.com/darwinOrg/go-common/result"
 	dghttp "github.com/darwinOrg/go-httpclient"
+	"github.com/darwinOrg/go-web/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -63,7 +64,7 @@ func SseMessage(messageChan chan *SseBody, event string, message any) {
 func SseForward(c *gin.Context, ctx *dgctx.DgContext, forwardUrl string) {
 	request, err := dghttp.CopyRequest(ctx, c.Request, forwardUrl, c.Request.Body)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(err))
+		middleware.AbortWithResult(c, result.SimpleFailByError(err))
 		return
 	}
 
@@ -71,7 +72,8 @@ func SseForward(c *gin.Context, ctx *dgctx.DgContext, forwardUrl string) {
 
 	resp, err := DefaultSseHttpClient.DoRequestRaw(ctx, request)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusOK, result.SimpleFailByError(err))
+		middleware.SetResultStatusHint(c, http.StatusBadGateway)
+		middleware.AbortWithResult(c, result.SimpleFailByError(err))
 		return
 	}
 
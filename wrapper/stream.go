@@ -0,0 +1,283 @@
+package wrapper
+
+import (
+	"bufio"
+	"bytes"
+	"iter"
+	"net/http"
+	"reflect"
+	"time"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/codec/json"
+)
+
+const (
+	MIMENDJSON = "application/x-ndjson"
+
+	streamBufferSize = 32 << 10
+)
+
+var (
+	DefaultStreamFlushItems    = 100         // 每输出多少个元素刷新一次
+	DefaultStreamFlushInterval = time.Second // 距上次刷新超过该时间时也会刷新
+)
+
+// StreamResponse BizHandler 返回该类型、iter.Seq[E] 或 iter.Seq2[E, error] 时逐个编码输出元素，不在内存中构造完整的列表。
+// 默认在标准的 result 结构中以 data 数组输出，Accept 为 application/x-ndjson 时每行输出一个元素。
+// 输出第一个元素之前出现错误时按失败结果输出；之后出现错误时 JSON 在 data 后追加 streamError 成员，NDJSON 追加一行 {"streamError":{...}}。
+// 客户端断开后停止遍历，迭代器中阻塞的操作应使用 c.Request.Context()，Timeout 只限制 BizHandler 本身。
+type StreamResponse struct {
+	each     func(yield func(any, error) bool)
+	elemType reflect.Type
+}
+
+// StreamError 输出过程中出现的错误
+type StreamError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func Stream[E any](seq iter.Seq[E]) *StreamResponse {
+	return &StreamResponse{
+		each: func(yield func(any, error) bool) {
+			if seq == nil {
+				return
+			}
+			for e := range seq {
+				if !yield(e, nil) {
+					return
+				}
+			}
+		},
+		elemType: reflect.TypeFor[E](),
+	}
+}
+
+// Stream2 迭代器返回的 error 不为空时停止输出并报告该错误
+func Stream2[E any](seq iter.Seq2[E, error]) *StreamResponse {
+	return &StreamResponse{
+		each: func(yield func(any, error) bool) {
+			if seq == nil {
+				return
+			}
+			for e, err := range seq {
+				if !yield(e, err) {
+					return
+				}
+			}
+		},
+		elemType: reflect.TypeFor[E](),
+	}
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// streamEnvelopePrefix、streamEnvelopeSuffix 成功结果在 data 值前后的部分，data 值替换为逐个输出的数组
+var streamEnvelopePrefix, streamEnvelopeSuffix = splitStreamEnvelope()
+
+func splitStreamEnvelope() ([]byte, []byte) {
+	bs, _ := json.API.Marshal(result.Success[any](nil))
+	i := bytes.LastIndex(bs, []byte(`"data":null`))
+	if i < 0 {
+		return []byte(`{"code":0,"message":"","success":true,"data":`), []byte("}")
+	}
+	return bs[:i+len(`"data":`)], bs[i+len(`"data":null`):]
+}
+
+// streamOf 把 BizHandler 的返回值转换为 StreamResponse，iter.Seq 和 iter.Seq2 的元素类型在编译期未知，通过反射调用
+func streamOf(rt any) (*StreamResponse, bool) {
+	if sr, ok := rt.(*StreamResponse); ok {
+		if sr == nil {
+			sr = &StreamResponse{each: func(yield func(any, error) bool) {}}
+		}
+		return sr, true
+	}
+
+	v := reflect.ValueOf(rt)
+	if !v.IsValid() {
+		return nil, false
+	}
+	elemType, withErr, ok := seqElemType(v.Type())
+	if !ok {
+		return nil, false
+	}
+
+	sr := &StreamResponse{elemType: elemType}
+	sr.each = func(yield func(any, error) bool) {
+		if v.IsNil() {
+			return
+		}
+		y := reflect.MakeFunc(v.Type().In(0), func(args []reflect.Value) []reflect.Value {
+			var err error
+			if withErr {
+				err, _ = args[1].Interface().(error)
+			}
+			return []reflect.Value{reflect.ValueOf(yield(args[0].Interface(), err))}
+		})
+		v.Call([]reflect.Value{y})
+	}
+	return sr, true
+}
+
+func isStreamResponse(rt any) bool {
+	_, ok := streamOf(rt)
+	return ok
+}
+
+// seqElemType 判断 t 是否为 func(yield func(E) bool) 或 func(yield func(E, error) bool)
+func seqElemType(t reflect.Type) (elemType reflect.Type, withErr bool, ok bool) {
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return nil, false, false
+	}
+	y := t.In(0)
+	if y.Kind() != reflect.Func || y.NumOut() != 1 || y.Out(0).Kind() != reflect.Bool {
+		return nil, false, false
+	}
+	switch {
+	case y.NumIn() == 1:
+		return y.In(0), false, true
+	case y.NumIn() == 2 && y.In(1) == errorType:
+		return y.In(0), true, true
+	}
+	return nil, false, false
+}
+
+// streamElemType 用于 OpenAPI，t 为去掉指针后的类型，StreamResponse 的元素类型只有运行时才知道，返回 nil
+func streamElemType(t reflect.Type) (reflect.Type, bool) {
+	if t == reflect.TypeOf(StreamResponse{}) {
+		return nil, true
+	}
+	elemType, _, ok := seqElemType(t)
+	return elemType, ok
+}
+
+func serveStream(c *gin.Context, ctx *dgctx.DgContext, formats []string, sr *StreamResponse) {
+	ndjson := acceptNDJSON(c.GetHeader("Accept"))
+	w := bufio.NewWriterSize(c.Writer, streamBufferSize)
+	reqCtx := c.Request.Context()
+
+	var (
+		count     int
+		started   bool
+		gone      bool
+		streamErr error
+		lastFlush = time.Now()
+	)
+	start := func() {
+		started = true
+		if ndjson {
+			c.Header("Content-Type", MIMENDJSON+"; charset=utf-8")
+		} else {
+			c.Header("Content-Type", gin.MIMEJSON+"; charset=utf-8")
+		}
+		// 避免 nginx 等反向代理缓冲整个响应
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		if !ndjson {
+			_, _ = w.Write(streamEnvelopePrefix)
+			_ = w.WriteByte('[')
+		}
+	}
+
+	sr.each(func(item any, err error) bool {
+		if reqCtx.Err() != nil {
+			gone = true
+			return false
+		}
+		if err != nil {
+			streamErr = err
+			return false
+		}
+		bs, err := json.API.Marshal(item)
+		if err != nil {
+			streamErr = err
+			return false
+		}
+
+		if !started {
+			start()
+		}
+		if !ndjson && count > 0 {
+			_ = w.WriteByte(',')
+		}
+		_, _ = w.Write(bs)
+		if ndjson {
+			_ = w.WriteByte('\n')
+		}
+		count++
+
+		if count%DefaultStreamFlushItems == 0 || time.Since(lastFlush) >= DefaultStreamFlushInterval {
+			if err := flushStream(c, w); err != nil {
+				gone = true
+				return false
+			}
+			lastFlush = time.Now()
+		}
+		return true
+	})
+
+	if gone {
+		dglogger.Warnf(ctx, "stream client gone | path: %s | count: %d", c.Request.URL.Path, count)
+		return
+	}
+	if streamErr != nil {
+		dglogger.Errorf(ctx, "stream response error | path: %s | count: %d | err: %v", c.Request.URL.Path, count, streamErr)
+		if !started {
+			rt := result.SimpleFailByError(streamErr)
+			renderResult(c, formats, middleware.ResultStatus(c, rt), rt)
+			return
+		}
+	}
+
+	if !started {
+		start()
+	}
+	var errBytes []byte
+	if streamErr != nil {
+		fail := result.SimpleFailByError(streamErr)
+		errBytes, _ = json.API.Marshal(&StreamError{Code: fail.Code, Message: fail.Message})
+	}
+	if ndjson {
+		if errBytes != nil {
+			_, _ = w.WriteString(`{"streamError":`)
+			_, _ = w.Write(errBytes)
+			_, _ = w.WriteString("}\n")
+		}
+	} else {
+		_ = w.WriteByte(']')
+		if errBytes != nil {
+			_, _ = w.WriteString(`,"streamError":`)
+			_, _ = w.Write(errBytes)
+		}
+		_, _ = w.Write(streamEnvelopeSuffix)
+	}
+	if err := flushStream(c, w); err != nil {
+		dglogger.Warnf(ctx, "stream client gone | path: %s | count: %d", c.Request.URL.Path, count)
+	}
+}
+
+func flushStream(c *gin.Context, w *bufio.Writer) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// acceptNDJSON Accept 中 application/x-ndjson 排在 JSON 之前时输出 NDJSON
+func acceptNDJSON(accept string) bool {
+	for _, mediaRange := range parseAccept(accept) {
+		if mediaRange == MIMENDJSON {
+			return true
+		}
+		if matchMediaRange(mediaRange, gin.MIMEJSON) {
+			return false
+		}
+	}
+	return false
+}
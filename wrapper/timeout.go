@@ -0,0 +1,245 @@
+package wrapper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	dgctx "github.com/darwinOrg/go-common/context"
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/darwinOrg/go-monitor"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const timeoutCounterName = "server_http_timeout_total"
+
+var ErrRequestTimeout = &dgerr.DgError{Code: http.StatusGatewayTimeout, Message: "request timeout"}
+
+// callBizHandlerWithTimeout 在独立的 goroutine 中执行 BizHandler，超时后立即返回超时结果。
+// 业务处理使用 gin.Context 的副本和缓冲的 ResponseWriter，被放弃的处理逻辑后续的写入会被丢弃，
+// 因此设置了 Timeout 的接口不支持 SSE 等流式输出。超时返回的 completed 为 false。
+// BizHandler 拿到的是请求对象的浅拷贝，正常返回后再复制回 req；超时后被放弃的处理逻辑修改请求对象不会影响日志和后置处理器，
+// 但切片、map 等引用类型的字段仍然共享，BizHandler 不应原地修改。上传的临时文件在被放弃的处理逻辑结束后才删除。
+func callBizHandlerWithTimeout[T any, V any](c *gin.Context, ctx *dgctx.DgContext, rh *RequestHolder[T, V], req *T) (rt any, completed bool) {
+	parent := ctx.GetInnerContext()
+	if parent == nil {
+		parent = c.Request.Context()
+	}
+	timeoutCtx, cancel := context.WithTimeout(parent, rh.Timeout)
+	defer cancel()
+
+	bizCtx := ctx.Clone()
+	bizCtx.SetInnerContext(timeoutCtx)
+
+	tw := newTimeoutWriter(c.Writer)
+	bc := c.Copy()
+	bc.Request = c.Request.WithContext(timeoutCtx)
+	bc.Writer = tw
+	bc.Set(utils.DgContextKey, bizCtx)
+
+	bizReq := *req
+	task := &timeoutTask{}
+	done := make(chan V, 1)
+	panicChan := make(chan any, 1)
+	go func() {
+		defer func() {
+			p := recover()
+			if p != nil {
+				panicChan <- p
+			}
+			if task.finish() {
+				// 请求已超时返回，panic 无法再交给 middleware.Recover，在这里记录并执行 RecoverProcessor
+				if p != nil {
+					middleware.ReportPanic(bc, bizCtx, p, debug.Stack())
+				}
+				removeUploadFiles(bc)
+			}
+		}()
+		done <- rh.BizHandler(bc, bizCtx, &bizReq)
+	}()
+
+	select {
+	case p := <-panicChan:
+		// 交给 middleware.Recover 统一处理
+		panic(p)
+	case rt := <-done:
+		*req = bizReq
+		for k, v := range bc.Keys {
+			if k != utils.DgContextKey {
+				c.Set(k, v)
+			}
+		}
+		c.Errors = append(c.Errors, bc.Errors...)
+		tw.flushTo(c.Writer)
+		return rt, true
+	case <-timeoutCtx.Done():
+		tw.timeout()
+		if task.abandon() {
+			// 上传文件交给仍在执行的 goroutine 清理
+			c.Set(uploadFilesKey, []*UploadFile(nil))
+		} else {
+			// BizHandler 恰好在超时时结束，panic 仍交给 middleware.Recover 处理
+			select {
+			case p := <-panicChan:
+				panic(p)
+			default:
+			}
+		}
+		path := c.FullPath()
+		dglogger.Warnf(ctx, "biz handler timeout, path: %s, timeout: %v", path, rh.Timeout)
+		_ = monitor.IncCounter(timeoutCounterName, map[string]string{"path": path})
+		return result.SimpleFailByError(ErrRequestTimeout), false
+	}
+}
+
+// timeoutTask 记录超时时 BizHandler 是否仍在执行，两者只有一方负责清理上传文件
+type timeoutTask struct {
+	mu        sync.Mutex
+	finished  bool
+	abandoned bool
+}
+
+// finish BizHandler 结束时调用，返回 true 表示请求已超时返回，需要由当前 goroutine 清理
+func (t *timeoutTask) finish() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.finished = true
+	return t.abandoned
+}
+
+// abandon 超时时调用，BizHandler 已经结束时返回 false，仍按正常流程清理
+func (t *timeoutTask) abandon() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.abandoned = !t.finished
+	return t.abandoned
+}
+
+type timeoutWriter struct {
+	w           gin.ResponseWriter
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{w: w, header: w.Header().Clone(), status: http.StatusOK}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteString(s string) (int, error) {
+	return tw.Write([]byte(s))
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = code
+}
+
+func (tw *timeoutWriter) WriteHeaderNow() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.status
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.wroteHeader {
+		return -1
+	}
+	return tw.body.Len()
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.wroteHeader
+}
+
+func (tw *timeoutWriter) Flush() {}
+
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijack is not supported when timeout is set")
+}
+
+func (tw *timeoutWriter) CloseNotify() <-chan bool {
+	return tw.w.CloseNotify()
+}
+
+func (tw *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
+
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.timedOut = true
+}
+
+func (tw *timeoutWriter) flushTo(w gin.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	dst := w.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			dst.Del(k)
+		}
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+
+	if tw.status != http.StatusOK || tw.wroteHeader {
+		w.WriteHeader(tw.status)
+	}
+	if tw.wroteHeader {
+		w.WriteHeaderNow()
+		_, _ = w.Write(tw.body.Bytes())
+	}
+}
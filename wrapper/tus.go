@@ -0,0 +1,373 @@
+package wrapper
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dgerr "github.com/darwinOrg/go-common/enums/error"
+	"github.com/darwinOrg/go-common/result"
+	dglogger "github.com/darwinOrg/go-logger"
+	"github.com/darwinOrg/go-web/middleware"
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	TusResumable        = "1.0.0"
+	TusExtensions       = "creation,creation-with-upload,termination,expiration"
+	MIMETusOffsetStream = "application/offset+octet-stream"
+
+	tusUploadKey = "TusUpload"
+)
+
+// TusConfig Store 为空时使用 DefaultTusStoreDir 目录的 FileTusStore
+type TusConfig struct {
+	Store      TusStore
+	MaxSize    int64
+	Expiration time.Duration
+}
+
+type tusServer[T any, V any] struct {
+	rh     *RequestHolder[T, V]
+	config TusConfig
+}
+
+// Tus 在 rh.RelativePath 上挂载 tus 1.0 断点续传服务，登录、角色、权限等检查与 Post 相同。
+// Upload-Metadata 按 form 标签绑定到 T，创建上传时就会校验；上传完成时调用 BizHandler，
+// 通过 GetTusUpload 取得上传的文件，完成的 PATCH 请求返回 200 和业务结果，失败时按结果返回错误状态码。
+//
+//	wrapper.Tus(&wrapper.RequestHolder[VideoMeta, *result.Result[string]]{
+//		RouterGroup: group, RelativePath: "videos", BizHandler: saveVideo,
+//	}, &wrapper.TusConfig{MaxSize: 4 << 30})
+func Tus[T any, V any](rh *RequestHolder[T, V], config *TusConfig) {
+	s := &tusServer[T, V]{rh: rh}
+	if config != nil {
+		s.config = *config
+	}
+	if s.config.Store == nil {
+		s.config.Store = NewFileTusStore(DefaultTusStoreDir)
+	}
+	if s.config.MaxSize == 0 {
+		s.config.MaxSize = DefaultTusMaxSize
+	}
+	if s.config.Expiration == 0 {
+		s.config.Expiration = DefaultTusExpiration
+	}
+
+	// tus 客户端依赖 HTTP 状态码，总是按 RestStatus 返回
+	guards := append(gin.HandlersChain{middleware.RestStatus(), tusResumableHandler()}, buildAccessHandlersChain(rh)...)
+	chain := func(h gin.HandlerFunc) gin.HandlersChain {
+		return append(slices.Clone(guards), h)
+	}
+
+	uploadPath := path.Join(rh.RelativePath, ":id")
+	rh.OPTIONS(rh.RelativePath, s.options)
+	rh.POST(rh.RelativePath, chain(s.create)...)
+	rh.HEAD(uploadPath, chain(s.head)...)
+	rh.PATCH(uploadPath, chain(s.patch)...)
+	rh.DELETE(uploadPath, chain(s.terminate)...)
+	registerRbacRoute(rh, http.MethodPost, rh.RelativePath)
+	registerRbacRoute(rh, http.MethodHead, uploadPath)
+	registerRbacRoute(rh, http.MethodPatch, uploadPath)
+	registerRbacRoute(rh, http.MethodDelete, uploadPath)
+}
+
+// GetTusUpload 在 Tus 的 BizHandler 中取得完成的上传
+func GetTusUpload(c *gin.Context) *TusUpload {
+	if upload, ok := c.Get(tusUploadKey); ok {
+		return upload.(*TusUpload)
+	}
+	return nil
+}
+
+func tusResumableHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Tus-Resumable", TusResumable)
+		if c.GetHeader("Tus-Resumable") != TusResumable {
+			c.Header("Tus-Version", TusResumable)
+			tusAbort(c, http.StatusPreconditionFailed, "unsupported Tus-Resumable version")
+			return
+		}
+		c.Next()
+	}
+}
+
+func tusAbort(c *gin.Context, status int, message string) {
+	middleware.SetResultStatusHint(c, status)
+	middleware.AbortWithResult(c, result.SimpleFailByError(&dgerr.DgError{Code: status, Message: message}))
+}
+
+func (s *tusServer[T, V]) options(c *gin.Context) {
+	c.Header("Tus-Resumable", TusResumable)
+	c.Header("Tus-Version", TusResumable)
+	c.Header("Tus-Extension", TusExtensions)
+	if s.config.MaxSize > 0 {
+		c.Header("Tus-Max-Size", strconv.FormatInt(s.config.MaxSize, 10))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *tusServer[T, V]) create(c *gin.Context) {
+	ctx := utils.GetDgContext(c)
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		tusAbort(c, http.StatusBadRequest, "invalid Upload-Length")
+		return
+	}
+	if s.config.MaxSize > 0 && size > s.config.MaxSize {
+		tusAbort(c, http.StatusRequestEntityTooLarge, "Upload-Length exceeds Tus-Max-Size")
+		return
+	}
+	metadata, err := parseTusMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		tusAbort(c, http.StatusBadRequest, "invalid Upload-Metadata")
+		return
+	}
+	if err = bindTusMetadata(new(T), metadata); err != nil {
+		middleware.SetResultStatusHint(c, http.StatusBadRequest)
+		middleware.SetBindError(c, err)
+		middleware.AbortWithResult(c, bindFailResult(ctx, err))
+		return
+	}
+
+	upload := &TusUpload{
+		Id:        newTusId(),
+		Size:      size,
+		Metadata:  metadata,
+		UserId:    ctx.UserId,
+		ExpiresAt: time.Now().Add(s.config.Expiration),
+	}
+	if err = s.config.Store.Create(c.Request.Context(), upload); err != nil {
+		dglogger.Errorf(ctx, "create tus upload error: %v", err)
+		tusAbort(c, http.StatusInternalServerError, "create upload error")
+		return
+	}
+	upload.store = s.config.Store
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+upload.Id)
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if (c.ContentType() == MIMETusOffsetStream && c.Request.ContentLength != 0) || size == 0 {
+		s.write(c, upload, http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
+
+func (s *tusServer[T, V]) head(c *gin.Context) {
+	upload, ok := s.load(c)
+	if !ok {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if len(upload.Metadata) > 0 {
+		c.Header("Upload-Metadata", encodeTusMetadata(upload.Metadata))
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *tusServer[T, V]) patch(c *gin.Context) {
+	if c.ContentType() != MIMETusOffsetStream {
+		tusAbort(c, http.StatusUnsupportedMediaType, "Content-Type must be "+MIMETusOffsetStream)
+		return
+	}
+	upload, ok := s.load(c)
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil {
+		tusAbort(c, http.StatusBadRequest, "invalid Upload-Offset")
+		return
+	}
+	if offset != upload.Offset {
+		tusAbort(c, http.StatusConflict, "Upload-Offset mismatch")
+		return
+	}
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	s.write(c, upload, http.StatusNoContent)
+}
+
+func (s *tusServer[T, V]) terminate(c *gin.Context) {
+	upload, ok := s.load(c)
+	if !ok {
+		return
+	}
+	if err := s.config.Store.Remove(c.Request.Context(), upload.Id); err != nil {
+		dglogger.Errorf(utils.GetDgContext(c), "remove tus upload error: %v", err)
+		tusAbort(c, http.StatusInternalServerError, "remove upload error")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// load 读取上传，不存在或属于其他用户时返回 404，过期时删除并返回 410
+func (s *tusServer[T, V]) load(c *gin.Context) (*TusUpload, bool) {
+	ctx := utils.GetDgContext(c)
+	upload, err := s.config.Store.Get(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, ErrTusUploadNotFound) || (err == nil && upload.UserId != 0 && upload.UserId != ctx.UserId) {
+		tusAbort(c, http.StatusNotFound, "upload not found")
+		return nil, false
+	}
+	if err != nil {
+		dglogger.Errorf(ctx, "get tus upload error: %v", err)
+		tusAbort(c, http.StatusInternalServerError, "get upload error")
+		return nil, false
+	}
+	if !upload.ExpiresAt.IsZero() && time.Now().After(upload.ExpiresAt) {
+		_ = s.config.Store.Remove(c.Request.Context(), upload.Id)
+		tusAbort(c, http.StatusGone, "upload expired")
+		return nil, false
+	}
+	upload.store = s.config.Store
+	return upload, true
+}
+
+// write 把请求体追加到上传，完成时交给 BizHandler
+func (s *tusServer[T, V]) write(c *gin.Context, upload *TusUpload, status int) {
+	ctx := utils.GetDgContext(c)
+	remaining := upload.Size - upload.Offset
+	if c.Request.ContentLength > remaining {
+		tusAbort(c, http.StatusRequestEntityTooLarge, "request body exceeds Upload-Length")
+		return
+	}
+
+	if remaining > 0 {
+		body := io.LimitReader(c.Request.Body, remaining)
+		offset, err := s.config.Store.Append(c.Request.Context(), upload.Id, upload.Offset, body)
+		if errors.Is(err, ErrTusOffsetMismatch) {
+			tusAbort(c, http.StatusConflict, "Upload-Offset mismatch")
+			return
+		}
+		upload.Offset = offset
+		c.Header("Upload-Offset", strconv.FormatInt(offset, 10))
+		if err != nil {
+			dglogger.Errorf(ctx, "append tus upload error | id: %s | offset: %d | err: %v", upload.Id, offset, err)
+			tusAbort(c, http.StatusInternalServerError, "append upload error")
+			return
+		}
+	} else {
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	}
+
+	if !upload.Completed() {
+		c.Status(status)
+		return
+	}
+	// 并发或重复的请求都可能看到写满的上传，只有把它标记为完成的请求调用 BizHandler
+	finished, err := s.config.Store.Finish(c.Request.Context(), upload.Id)
+	if err != nil {
+		dglogger.Errorf(ctx, "finish tus upload error | id: %s | err: %v", upload.Id, err)
+		tusAbort(c, http.StatusInternalServerError, "finish upload error")
+		return
+	}
+	if !finished {
+		c.Status(status)
+		return
+	}
+	s.complete(c, upload)
+}
+
+func (s *tusServer[T, V]) complete(c *gin.Context, upload *TusUpload) {
+	start := time.Now()
+	ctx := utils.GetDgContext(c)
+	c.Set(tusUploadKey, upload)
+
+	var rt any
+	req := new(T)
+	err := bindTusMetadata(req, upload.Metadata)
+	if err == nil {
+		err = validateRequest(ctx, req)
+	}
+	if err != nil {
+		middleware.SetResultStatusHint(c, http.StatusBadRequest)
+		middleware.SetBindError(c, err)
+		rt = bindFailResult(ctx, err)
+	} else {
+		rt, _ = invokeBizHandler(c, ctx, s.rh, req)
+	}
+	utils.SetRequestStructParam(c, req)
+
+	cost := time.Since(start)
+	if !c.Writer.Written() {
+		if isSuccessResult(rt) {
+			renderResult(c, s.rh.Formats, http.StatusOK, rt)
+		} else {
+			middleware.AbortWithResult(c, rt)
+		}
+	}
+
+	ll := s.rh.LogLevel
+	if ll == 0 {
+		ll = DEFAULT_LOG_LEVEL
+	}
+	if ll != LOG_LEVEL_NONE && shouldLogBiz(s.rh, rt, cost) {
+		printBizHandlerLog(c, ctx, req, rt, cost, ll, newBizLogOptions(s.rh))
+	}
+}
+
+func bindTusMetadata(req any, metadata map[string]string) error {
+	form := make(map[string][]string, len(metadata))
+	for k, v := range metadata {
+		form[k] = []string{v}
+	}
+	if err := applyDefaults(reflect.ValueOf(req), true); err != nil {
+		return err
+	}
+	if err := binding.MapFormWithTag(req, form, "form"); err != nil {
+		return err
+	}
+	return finishBind(req)
+}
+
+// parseTusMetadata Upload-Metadata 格式为逗号分隔的 "key base64(value)"，value 可以省略
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, " ")
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+func encodeTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + " " + base64.StdEncoding.EncodeToString([]byte(metadata[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func newTusId() string {
+	bs := make([]byte, 16)
+	_, _ = rand.Read(bs)
+	return hex.EncodeToString(bs)
+}
@@ -0,0 +1,234 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTusUploadNotFound = errors.New("tus upload not found")
+	ErrTusOffsetMismatch = errors.New("tus upload offset mismatch")
+)
+
+var (
+	DefaultTusStoreDir   = filepath.Join(os.TempDir(), "tus")
+	DefaultTusExpiration = 24 * time.Hour
+	DefaultTusMaxSize    = int64(0) // 单个上传的最大字节数，0 表示不限制
+)
+
+// TusUpload 一次 tus 上传，Metadata 为客户端 Upload-Metadata 解码后的键值
+type TusUpload struct {
+	Id         string            `json:"id"`
+	Size       int64             `json:"size"`
+	Offset     int64             `json:"offset"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	UserId     int64             `json:"userId,omitempty"`
+	ExpiresAt  time.Time         `json:"expiresAt"`
+	FinishedAt time.Time         `json:"finishedAt,omitempty"` // 由 Finish 记录，完成回调只在此时调用
+	store      TusStore
+}
+
+func (u *TusUpload) Completed() bool {
+	return u.Offset >= u.Size
+}
+
+func (u *TusUpload) Open(ctx context.Context) (io.ReadCloser, error) {
+	return u.store.Open(ctx, u.Id)
+}
+
+// TusStore tus 上传的存储，Append 需要校验 offset 并在写入中断时保留已写入的部分，返回新的 offset。
+// Finish 在上传的锁内把已写满的上传标记为完成，只有完成状态发生变化的那一次调用返回 true。
+type TusStore interface {
+	Create(ctx context.Context, upload *TusUpload) error
+	Get(ctx context.Context, id string) (*TusUpload, error)
+	Append(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+	Finish(ctx context.Context, id string) (bool, error)
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	Remove(ctx context.Context, id string) error
+}
+
+// FileTusStore 保存在本地目录，每个上传对应 id.bin 数据文件和 id.info 描述文件
+type FileTusStore struct {
+	Dir   string
+	locks sync.Map
+}
+
+func NewFileTusStore(dir string) *FileTusStore {
+	return &FileTusStore{Dir: dir}
+}
+
+func (s *FileTusStore) Create(_ context.Context, upload *TusUpload) error {
+	if !isTusId(upload.Id) {
+		return ErrTusUploadNotFound
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.dataPath(upload.Id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return s.writeInfo(upload)
+}
+
+func (s *FileTusStore) Get(_ context.Context, id string) (*TusUpload, error) {
+	if !isTusId(id) {
+		return nil, ErrTusUploadNotFound
+	}
+	bs, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTusUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &TusUpload{}
+	if err = json.Unmarshal(bs, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (s *FileTusStore) Append(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	upload, err := s.Get(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if upload.Offset != offset {
+		return upload.Offset, ErrTusOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return upload.Offset, err
+	}
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	// 中断时也记录已经写入的部分，客户端从新的 offset 继续上传
+	upload.Offset += n
+	if werr := s.writeInfo(upload); err == nil {
+		err = werr
+	}
+	return upload.Offset, err
+}
+
+func (s *FileTusStore) Finish(ctx context.Context, id string) (bool, error) {
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	upload, err := s.Get(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !upload.Completed() || !upload.FinishedAt.IsZero() {
+		return false, nil
+	}
+	upload.FinishedAt = time.Now()
+	return true, s.writeInfo(upload)
+}
+
+func (s *FileTusStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	if !isTusId(id) {
+		return nil, ErrTusUploadNotFound
+	}
+	return os.Open(s.dataPath(id))
+}
+
+func (s *FileTusStore) Remove(_ context.Context, id string) error {
+	if !isTusId(id) {
+		return ErrTusUploadNotFound
+	}
+	// 等待正在写入的请求结束后再删除，锁在持有期间从 locks 中移除，之后的请求读取不到上传
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+	defer s.locks.Delete(id)
+
+	if err := os.Remove(s.infoPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(s.dataPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// RemoveExpired 删除 now 之前过期的上传，可以由定时任务调用
+func (s *FileTusStore) RemoveExpired(ctx context.Context, now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".info")
+		if !ok {
+			continue
+		}
+		upload, err := s.Get(ctx, id)
+		if err != nil || upload.ExpiresAt.IsZero() || upload.ExpiresAt.After(now) {
+			continue
+		}
+		if err = s.Remove(ctx, id); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (s *FileTusStore) lock(id string) *sync.Mutex {
+	mu, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+func (s *FileTusStore) writeInfo(upload *TusUpload) error {
+	bs, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	tmp := s.infoPath(upload.Id) + ".tmp"
+	if err = os.WriteFile(tmp, bs, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(upload.Id))
+}
+
+func (s *FileTusStore) dataPath(id string) string {
+	return filepath.Join(s.Dir, id+".bin")
+}
+
+func (s *FileTusStore) infoPath(id string) string {
+	return filepath.Join(s.Dir, id+".info")
+}
+
+// isTusId 只接受 32 位十六进制的 id，避免路径穿越
+func isTusId(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
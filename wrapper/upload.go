@@ -0,0 +1,470 @@
+package wrapper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/darwinOrg/go-web/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	UPLOAD_TAG     = "upload"
+	uploadFilesKey = "UploadFiles"
+	sniffLen       = 512
+)
+
+var (
+	DefaultMaxMultipartMemory int64         = 8 << 20  // 解析 multipart 表单时保存在内存中的最大字节数，超过的部分写入临时文件
+	DefaultUploadMaxSize      int64         = 32 << 20 // 单个上传文件的默认最大字节数，upload 标签的 maxSize 可以覆盖
+	DefaultUploadStorage      UploadStorage = NewLocalUploadStorage("")
+)
+
+var (
+	errUploadTooLarge = errors.New("upload file too large")
+	fileHeaderType    = reflect.TypeOf(&multipart.FileHeader{})
+	uploadFileType    = reflect.TypeOf(&UploadFile{})
+)
+
+// UploadStorage 上传文件的存储，Save 时文件内容以流的方式读取，不会整体读入内存
+type UploadStorage interface {
+	Save(ctx context.Context, file *UploadFile, r io.Reader) (key string, err error)
+	Open(key string) (io.ReadCloser, error)
+	Remove(key string) error
+}
+
+// UploadFile 流式保存到 UploadStorage 的上传文件，请求结束后没有调用 Keep 的文件会被删除
+type UploadFile struct {
+	Field       string
+	Filename    string
+	Size        int64
+	ContentType string // 根据文件内容识别的类型
+	Header      textproto.MIMEHeader
+	Key         string
+	storage     UploadStorage
+	keep        bool
+}
+
+func (f *UploadFile) Open() (io.ReadCloser, error) {
+	return f.storage.Open(f.Key)
+}
+
+// Keep 请求结束后保留文件，之后由调用方负责删除
+func (f *UploadFile) Keep() {
+	f.keep = true
+}
+
+func (f *UploadFile) Remove() error {
+	return f.storage.Remove(f.Key)
+}
+
+// LocalUploadStorage 保存到本地目录，Dir 为空时使用系统临时目录
+type LocalUploadStorage struct {
+	Dir string
+}
+
+func NewLocalUploadStorage(dir string) *LocalUploadStorage {
+	return &LocalUploadStorage{Dir: dir}
+}
+
+func (s *LocalUploadStorage) Save(_ context.Context, file *UploadFile, r io.Reader) (string, error) {
+	f, err := os.CreateTemp(s.Dir, "upload-*"+filepath.Ext(file.Filename))
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func (s *LocalUploadStorage) Open(key string) (io.ReadCloser, error) {
+	return os.Open(key)
+}
+
+func (s *LocalUploadStorage) Remove(key string) error {
+	return os.Remove(key)
+}
+
+// uploadSpec upload 标签，如 upload:"maxSize=5MB,mime=image/png|image/*,count=3"
+type uploadSpec struct {
+	maxSize int64
+	mimes   []string
+	count   int
+}
+
+func parseUploadSpec(sf reflect.StructField) (*uploadSpec, error) {
+	spec := &uploadSpec{maxSize: DefaultUploadMaxSize}
+	if sf.Type.Kind() != reflect.Slice {
+		spec.count = 1
+	}
+
+	tag := sf.Tag.Get(UPLOAD_TAG)
+	for _, opt := range strings.Split(tag, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch k {
+		case "":
+		case "maxSize":
+			size, err := parseByteSize(v)
+			if err != nil {
+				return nil, fmt.Errorf("field %s upload tag %q: %w", sf.Name, tag, err)
+			}
+			spec.maxSize = size
+		case "mime":
+			spec.mimes = strings.Split(v, "|")
+		case "count":
+			count, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("field %s upload tag %q: %w", sf.Name, tag, err)
+			}
+			if spec.count == 0 {
+				spec.count = count
+			}
+		default:
+			return nil, fmt.Errorf("field %s upload tag %q: unknown option %s", sf.Name, tag, k)
+		}
+	}
+	return spec, nil
+}
+
+// parseByteSize 支持 1024、512KB、10MB、1GB
+func parseByteSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, unit := range units {
+		if num, ok := strings.CutSuffix(s, unit.suffix); ok {
+			n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+			return n * unit.size, err
+		}
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func (spec *uploadSpec) checkMime(field string, contentType string) error {
+	if len(spec.mimes) == 0 {
+		return nil
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	for _, m := range spec.mimes {
+		if matchMediaRange(strings.TrimSpace(m), mediaType) {
+			return nil
+		}
+	}
+	return fmt.Errorf("file %s: content type %s is not allowed", field, mediaType)
+}
+
+func (spec *uploadSpec) checkCount(field string, count int) error {
+	if spec.count > 0 && count > spec.count {
+		return fmt.Errorf("file %s: at most %d files", field, spec.count)
+	}
+	return nil
+}
+
+func (spec *uploadSpec) tooLarge(field string) error {
+	return fmt.Errorf("file %s: exceeds max size %d bytes", field, spec.maxSize)
+}
+
+type uploadField struct {
+	name  string
+	spec  *uploadSpec
+	value reflect.Value
+}
+
+// collectUploadFields 查找 *multipart.FileHeader、*UploadFile 以及它们的切片类型的字段，按 form 标签命名
+func collectUploadFields(v reflect.Value, fields *[]*uploadField) error {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := range v.NumField() {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		if (sf.PkgPath != "" && !sf.Anonymous) || !fv.CanSet() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		elemType := sf.Type
+		if elemType.Kind() == reflect.Slice {
+			elemType = elemType.Elem()
+		}
+		switch {
+		case elemType == fileHeaderType || elemType == uploadFileType:
+			spec, err := parseUploadSpec(sf)
+			if err != nil {
+				return err
+			}
+			*fields = append(*fields, &uploadField{name: name, spec: spec, value: fv})
+		case sf.Type.Kind() == reflect.Struct:
+			if err := collectUploadFields(fv, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hasUploadFileField(fields []*uploadField) bool {
+	for _, field := range fields {
+		if field.value.Type() == uploadFileType || field.value.Type().Elem() == uploadFileType {
+			return true
+		}
+	}
+	return false
+}
+
+// bindMultipart 请求对象中有 *UploadFile 字段时逐个读取 part 流式保存（此时忽略 *multipart.FileHeader 字段），
+// 否则交给 gin 按 MaxMultipartMemory 解析 multipart 表单
+func bindMultipart(c *gin.Context, storage UploadStorage, req any) error {
+	var fields []*uploadField
+	if err := collectUploadFields(reflect.ValueOf(req), &fields); err != nil {
+		return err
+	}
+	if hasUploadFileField(fields) {
+		return bindMultipartStream(c, storage, req, fields)
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return err
+	}
+	if err = binding.MapFormWithTag(req, form.Value, "form"); err != nil {
+		return err
+	}
+	return bindFileHeaders(fields, form.File)
+}
+
+func bindFileHeaders(fields []*uploadField, files map[string][]*multipart.FileHeader) error {
+	for _, field := range fields {
+		fhs := files[field.name]
+		if len(fhs) == 0 {
+			continue
+		}
+		if err := field.spec.checkCount(field.name, len(fhs)); err != nil {
+			return err
+		}
+		for _, fh := range fhs {
+			if field.spec.maxSize > 0 && fh.Size > field.spec.maxSize {
+				return field.spec.tooLarge(field.name)
+			}
+			contentType, err := sniffFileHeader(fh)
+			if err != nil {
+				return err
+			}
+			if err = field.spec.checkMime(field.name, contentType); err != nil {
+				return err
+			}
+		}
+
+		if field.value.Kind() == reflect.Slice {
+			field.value.Set(reflect.ValueOf(fhs))
+		} else {
+			field.value.Set(reflect.ValueOf(fhs[0]))
+		}
+	}
+	return nil
+}
+
+func sniffFileHeader(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+func bindMultipartStream(c *gin.Context, storage UploadStorage, req any, fields []*uploadField) (err error) {
+	if storage == nil {
+		storage = DefaultUploadStorage
+	}
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	var saved []*UploadFile
+	defer func() {
+		if err != nil {
+			for _, file := range saved {
+				_ = file.Remove()
+			}
+			return
+		}
+		addUploadFiles(c, saved)
+	}()
+
+	specs := make(map[string]*uploadField, len(fields))
+	for _, field := range fields {
+		specs[field.name] = field
+	}
+	values := map[string][]string{}
+	files := map[string][]*UploadFile{}
+	valueBytes := DefaultMaxMultipartMemory
+
+	for {
+		part, perr := reader.NextPart()
+		if errors.Is(perr, io.EOF) {
+			break
+		}
+		if perr != nil {
+			return perr
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			var buf bytes.Buffer
+			n, rerr := io.CopyN(&buf, part, valueBytes+1)
+			if rerr != nil && !errors.Is(rerr, io.EOF) {
+				return rerr
+			}
+			if valueBytes -= n; valueBytes < 0 {
+				return errors.New("multipart: form values too large")
+			}
+			values[name] = append(values[name], buf.String())
+			continue
+		}
+
+		field, ok := specs[name]
+		if !ok || (field.value.Type() != uploadFileType && field.value.Type().Elem() != uploadFileType) {
+			continue
+		}
+		if err = field.spec.checkCount(name, len(files[name])+1); err != nil {
+			return err
+		}
+
+		file, serr := saveUploadPart(c.Request.Context(), storage, field.spec, part)
+		if serr != nil {
+			return serr
+		}
+		saved = append(saved, file)
+		files[name] = append(files[name], file)
+	}
+
+	utils.SetMultipartValues(c, values)
+	if err = binding.MapFormWithTag(req, values, "form"); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		uploads := files[field.name]
+		if len(uploads) == 0 {
+			continue
+		}
+		if field.value.Kind() == reflect.Slice {
+			field.value.Set(reflect.ValueOf(uploads))
+		} else {
+			field.value.Set(reflect.ValueOf(uploads[0]))
+		}
+	}
+	return nil
+}
+
+func saveUploadPart(ctx context.Context, storage UploadStorage, spec *uploadSpec, part *multipart.Part) (*UploadFile, error) {
+	name := part.FormName()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	file := &UploadFile{
+		Field:       name,
+		Filename:    part.FileName(),
+		ContentType: http.DetectContentType(head),
+		Header:      part.Header,
+		storage:     storage,
+	}
+	if err = spec.checkMime(name, file.ContentType); err != nil {
+		return nil, err
+	}
+
+	ur := &uploadReader{r: io.MultiReader(bytes.NewReader(head), part), max: spec.maxSize}
+	file.Key, err = storage.Save(ctx, file, ur)
+	if errors.Is(err, errUploadTooLarge) {
+		return nil, spec.tooLarge(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.Size = ur.n
+	return file, nil
+}
+
+// uploadReader 统计读取的字节数，超过 max 时返回 errUploadTooLarge 中止保存
+type uploadReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func (ur *uploadReader) Read(p []byte) (int, error) {
+	n, err := ur.r.Read(p)
+	ur.n += int64(n)
+	if ur.max > 0 && ur.n > ur.max {
+		return n, errUploadTooLarge
+	}
+	return n, err
+}
+
+func addUploadFiles(c *gin.Context, files []*UploadFile) {
+	if len(files) == 0 {
+		return
+	}
+	if existing, ok := c.Get(uploadFilesKey); ok {
+		files = append(existing.([]*UploadFile), files...)
+	}
+	c.Set(uploadFilesKey, files)
+}
+
+// removeUploadFiles 请求结束时删除没有调用 Keep 的上传文件
+func removeUploadFiles(c *gin.Context) {
+	files, ok := c.Get(uploadFilesKey)
+	if !ok {
+		return
+	}
+	for _, file := range files.([]*UploadFile) {
+		if !file.keep {
+			_ = file.Remove()
+		}
+	}
+}
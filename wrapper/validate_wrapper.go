@@ -1,14 +1,30 @@
 package wrapper
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 
+	dgctx "github.com/darwinOrg/go-common/context"
+	"github.com/darwinOrg/go-common/result"
 	ve "github.com/darwinOrg/go-validator-ext"
+	"github.com/darwinOrg/go-web/utils"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
+// ValidateFailResult 参数校验失败的结果，message 保持原有的合并信息，errors 为字段级的错误
+type ValidateFailResult struct {
+	*result.Result[*result.Void]
+	Errors []*utils.FieldError `json:"errors,omitempty"`
+}
+
+// RequestValidator 请求对象实现该接口时，在参数绑定和标签校验通过后调用，返回的错误与标签校验错误一样输出，
+// 需要指定出错的字段时返回 utils.FieldErrors
+type RequestValidator interface {
+	Validate(ctx *dgctx.DgContext) error
+}
+
 var candidateValidatorTags = []string{"remark", "json", "form", "title", "label", "desc"}
 
 func init() {
@@ -31,3 +47,58 @@ func init() {
 		ve.CustomValidator = v
 	}
 }
+
+// RegisterValidation 注册字段校验规则，messages 为各语言的错误信息，{0} 替换为字段名，{1} 替换为规则参数
+//
+//	wrapper.RegisterValidation("sku", isSku, map[string]string{"zh": "{0}不是合法的SKU", "en": "{0} is not a valid SKU"})
+func RegisterValidation(tag string, fn validator.Func, messages map[string]string) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validator engine is not go-playground validator")
+	}
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	utils.RegisterValidationMessages(tag, messages)
+	return nil
+}
+
+// RegisterStructValidation 注册结构体级别的校验规则，用于跨字段的校验，例如结束日期晚于开始日期：
+//
+//	wrapper.RegisterStructValidation(func(sl validator.StructLevel) {
+//		req := sl.Current().Interface().(QueryRequest)
+//		if req.EndDate.Before(req.StartDate) {
+//			sl.ReportError(req.EndDate, "endDate", "EndDate", "gtStartDate", "")
+//		}
+//	}, QueryRequest{})
+//	wrapper.RegisterValidationMessages("gtStartDate", map[string]string{"zh": "{0}必须晚于开始日期"})
+func RegisterStructValidation(fn validator.StructLevelFunc, types ...any) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validator engine is not go-playground validator")
+	}
+	v.RegisterStructValidation(fn, types...)
+	return nil
+}
+
+// RegisterAlias 为一组校验规则注册别名，例如 RegisterAlias("password", "min=8,max=32", ...)
+func RegisterAlias(alias string, tags string, messages map[string]string) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validator engine is not go-playground validator")
+	}
+	v.RegisterAlias(alias, tags)
+	utils.RegisterValidationMessages(alias, messages)
+	return nil
+}
+
+func RegisterValidationMessages(tag string, messages map[string]string) {
+	utils.RegisterValidationMessages(tag, messages)
+}
+
+func validateRequest(ctx *dgctx.DgContext, req any) error {
+	if rv, ok := req.(RequestValidator); ok {
+		return rv.Validate(ctx)
+	}
+	return nil
+}